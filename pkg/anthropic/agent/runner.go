@@ -0,0 +1,227 @@
+// Package agent drives tool use conversations with Claude, executing the tools the model asks for
+// with local Go handlers until the model produces a final answer.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/client"
+)
+
+// DefaultMaxIterations is the maximum number of requests a Runner sends when none is configured.
+const DefaultMaxIterations = 10
+
+var (
+	ErrClientRequired = errors.New("client is required")
+	ErrMaxIterations  = errors.New("maximum number of iterations reached before the model finished")
+)
+
+// ToolHandler executes a tool call requested by the model. The returned value is sent back as the
+// tool result: strings and content blocks are sent as-is, anything else is encoded as JSON.
+// Returning an error sends the error message back with is_error set.
+type ToolHandler func(ctx context.Context, input map[string]interface{}) (interface{}, error)
+
+type Config struct {
+	Client client.Client
+	// Handlers are keyed by the Tool.Name they execute.
+	Handlers map[string]ToolHandler
+	// Optional (defaults to DefaultMaxIterations)
+	MaxIterations int
+}
+
+type Runner struct {
+	client        client.Client
+	handlers      map[string]ToolHandler
+	maxIterations int
+}
+
+// Turn is a single request/response round trip of a Run.
+type Turn struct {
+	Response *anthropic.MessageResponse
+	// ToolResults are the tool_result blocks sent back to the model in reply to Response.
+	ToolResults []anthropic.ContentBlock
+}
+
+// Result is the outcome of a Run.
+type Result struct {
+	// Response is the last response received from the model.
+	Response *anthropic.MessageResponse
+	// Messages is the full conversation, including the final assistant message.
+	Messages []anthropic.MessagePartRequest
+	// Turns is the transcript of every round trip made.
+	Turns []Turn
+}
+
+func MakeRunner(cfg Config) (*Runner, error) {
+	if cfg.Client == nil {
+		return nil, ErrClientRequired
+	}
+
+	if cfg.MaxIterations <= 0 {
+		cfg.MaxIterations = DefaultMaxIterations
+	}
+
+	handlers := make(map[string]ToolHandler, len(cfg.Handlers))
+	for name, handler := range cfg.Handlers {
+		handlers[name] = handler
+	}
+
+	return &Runner{
+		client:        cfg.Client,
+		handlers:      handlers,
+		maxIterations: cfg.MaxIterations,
+	}, nil
+}
+
+// Run sends req and keeps executing the requested tools and sending their results back until the
+// model stops asking for tools. The caller's request is not modified. When the iteration cap is
+// hit, the partial Result is returned along with ErrMaxIterations.
+func (r *Runner) Run(ctx context.Context, req *anthropic.MessageRequest) (*Result, error) {
+	conversation := *req
+	conversation.Messages = append([]anthropic.MessagePartRequest(nil), req.Messages...)
+
+	result := &Result{}
+
+	for iteration := 0; iteration < r.maxIterations; iteration++ {
+		response, err := r.client.Message(ctx, &conversation)
+		if err != nil {
+			return result, fmt.Errorf("error sending message request: %w", err)
+		}
+
+		conversation.AddAssistantMessage(responseContentBlocks(response)...)
+		result.Response = response
+		result.Messages = conversation.Messages
+
+		if response.StopReason != "tool_use" {
+			result.Turns = append(result.Turns, Turn{Response: response})
+			return result, nil
+		}
+
+		toolResults := r.executeTools(ctx, conversation.Tools, response)
+		result.Turns = append(result.Turns, Turn{Response: response, ToolResults: toolResults})
+
+		conversation.AddUserMessage(toolResults...)
+		result.Messages = conversation.Messages
+	}
+
+	return result, ErrMaxIterations
+}
+
+// executeTools runs every tool_use block of the response concurrently and returns their results
+// in the order the model requested them.
+func (r *Runner) executeTools(ctx context.Context, tools []anthropic.Tool, response *anthropic.MessageResponse) []anthropic.ContentBlock {
+	toolUses := response.ToolUses()
+	results := make([]anthropic.ContentBlock, len(toolUses))
+
+	var wg sync.WaitGroup
+	for i, toolUse := range toolUses {
+		wg.Add(1)
+		go func(i int, toolUse anthropic.ToolUseContentBlock) {
+			defer wg.Done()
+			results[i] = r.executeTool(ctx, tools, toolUse)
+		}(i, toolUse)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// executeTool validates the input of a tool_use block against the declared tool's InputSchema and
+// runs its handler. Invalid inputs are reported back to the model without calling the handler.
+func (r *Runner) executeTool(ctx context.Context, tools []anthropic.Tool, toolUse anthropic.ToolUseContentBlock) (result anthropic.ContentBlock) {
+	handler, ok := r.handlers[toolUse.Name]
+	if !ok {
+		return anthropic.NewToolResultContentBlock(toolUse.ID, fmt.Sprintf("unknown tool: %s", toolUse.Name), true)
+	}
+
+	for _, tool := range tools {
+		if tool.Name != toolUse.Name || tool.InputSchema.Type == "" {
+			continue
+		}
+
+		err := anthropic.ValidateToolInput(tool.InputSchema, toolUse.Input)
+		if validationErr, ok := err.(*anthropic.ToolInputValidationError); ok {
+			validationErr.ToolName = tool.Name
+			return validationErr.ToolResult(toolUse.ID)
+		}
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			result = anthropic.NewToolResultContentBlock(toolUse.ID, fmt.Sprintf("tool panicked: %v", p), true)
+		}
+	}()
+
+	input, err := toolInputMap(toolUse.Input)
+	if err != nil {
+		return anthropic.NewToolResultContentBlock(toolUse.ID, err.Error(), true)
+	}
+
+	output, err := handler(ctx, input)
+	if err != nil {
+		return anthropic.NewToolResultContentBlock(toolUse.ID, err.Error(), true)
+	}
+
+	content, err := toolResultContent(output)
+	if err != nil {
+		return anthropic.NewToolResultContentBlock(toolUse.ID, fmt.Sprintf("error encoding tool result: %s", err), true)
+	}
+
+	return anthropic.NewToolResultContentBlock(toolUse.ID, content, false)
+}
+
+// toolResultContent converts a handler's output to a value accepted as tool_result content.
+func toolResultContent(output interface{}) (interface{}, error) {
+	switch v := output.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case anthropic.ContentBlock:
+		return []anthropic.ContentBlock{v}, nil
+	case []anthropic.ContentBlock:
+		return v, nil
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// toolInputMap returns the input of a tool_use block as the map handlers receive.
+func toolInputMap(input interface{}) (map[string]interface{}, error) {
+	switch v := input.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return v, nil
+	}
+
+	decoded := map[string]interface{}{}
+	err := anthropic.DecodeToolInput(input, &decoded)
+	if err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
+// responseContentBlocks returns the content of a response to be sent back as the assistant's turn,
+// leaving out empty text blocks which the API rejects.
+func responseContentBlocks(response *anthropic.MessageResponse) []anthropic.ContentBlock {
+	blocks := []anthropic.ContentBlock{}
+	for _, block := range response.Content {
+		if text, ok := block.(anthropic.TextContentBlock); ok && text.Text == "" {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
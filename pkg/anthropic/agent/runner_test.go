@@ -0,0 +1,277 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+// fakeClient replays canned responses and records the requests it receives.
+type fakeClient struct {
+	mu        sync.Mutex
+	responses []*anthropic.MessageResponse
+	requests  []anthropic.MessageRequest
+}
+
+func (f *fakeClient) Message(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	snapshot := *req
+	snapshot.Messages = append([]anthropic.MessagePartRequest(nil), req.Messages...)
+	f.requests = append(f.requests, snapshot)
+
+	if len(f.responses) == 0 {
+		return nil, errors.New("no more responses")
+	}
+	response := f.responses[0]
+	f.responses = f.responses[1:]
+	return response, nil
+}
+
+func (f *fakeClient) MessageStream(ctx context.Context, req *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+	msCh := make(chan *anthropic.MessageStreamResponse)
+	errCh := make(chan error, 1)
+	errCh <- errors.New("not implemented")
+	close(msCh)
+	close(errCh)
+	return msCh, errCh
+}
+
+func (f *fakeClient) CountTokens(ctx context.Context, req *anthropic.MessageRequest) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func toolUseResponse(parts ...anthropic.ContentBlock) *anthropic.MessageResponse {
+	return &anthropic.MessageResponse{
+		Role:       "assistant",
+		Content:    parts,
+		StopReason: "tool_use",
+	}
+}
+
+func endTurnResponse(text string) *anthropic.MessageResponse {
+	return &anthropic.MessageResponse{
+		Role:       "assistant",
+		Content:    []anthropic.ContentBlock{anthropic.NewTextContentBlock(text)},
+		StopReason: "end_turn",
+	}
+}
+
+func newRequest() *anthropic.MessageRequest {
+	return anthropic.NewMessageRequest(
+		anthropic.WithMessageModel(anthropic.Claude35Sonnet),
+		anthropic.WithMessageMaxTokens(512),
+	).AddUserMessage(anthropic.NewTextContentBlock("What's the weather in Charleston and Boston?"))
+}
+
+func TestRunnerExecutesToolsInParallel(t *testing.T) {
+	fake := &fakeClient{
+		responses: []*anthropic.MessageResponse{
+			toolUseResponse(
+				anthropic.NewTextContentBlock("Checking both cities."),
+				anthropic.ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{"city": "Charleston"}},
+				anthropic.ToolUseContentBlock{Type: "tool_use", ID: "toolu_02", Name: "get_weather", Input: map[string]interface{}{"city": "Boston"}},
+			),
+			endTurnResponse("It is 52f in Charleston and 40f in Boston."),
+		},
+	}
+
+	// both handlers must be running at the same time for either to return
+	var started sync.WaitGroup
+	started.Add(2)
+
+	runner, err := MakeRunner(Config{
+		Client: fake,
+		Handlers: map[string]ToolHandler{
+			"get_weather": func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				started.Done()
+				started.Wait()
+				if input["city"] == "Charleston" {
+					return "52f", nil
+				}
+				return map[string]int{"temperature": 40}, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := newRequest()
+	result, err := runner.Run(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(request.Messages) != 1 {
+		t.Errorf("Expected the caller's request to be left untouched, got %d messages", len(request.Messages))
+	}
+
+	if len(result.Turns) != 2 {
+		t.Fatalf("Expected 2 turns, got %d", len(result.Turns))
+	}
+
+	if result.Response.Text() != "It is 52f in Charleston and 40f in Boston." {
+		t.Errorf("Unexpected final response %q", result.Response.Text())
+	}
+
+	toolResults := result.Turns[0].ToolResults
+	if len(toolResults) != 2 {
+		t.Fatalf("Expected 2 tool results, got %d", len(toolResults))
+	}
+
+	first := toolResults[0].(anthropic.ToolResultContentBlock)
+	second := toolResults[1].(anthropic.ToolResultContentBlock)
+	if first.ToolUseID != "toolu_01" || first.Content != "52f" || first.IsError {
+		t.Errorf("Unexpected first tool result %+v", first)
+	}
+	if second.ToolUseID != "toolu_02" || second.Content != `{"temperature":40}` || second.IsError {
+		t.Errorf("Unexpected second tool result %+v", second)
+	}
+
+	// user, assistant (tool_use), user (tool_result), assistant (final)
+	if len(result.Messages) != 4 {
+		t.Fatalf("Expected 4 messages in the conversation, got %d", len(result.Messages))
+	}
+
+	secondRequest := fake.requests[1]
+	if len(secondRequest.Messages) != 3 || secondRequest.Messages[2].Role != "user" {
+		t.Errorf("Expected tool results to be sent back as a user message, got %+v", secondRequest.Messages)
+	}
+}
+
+func TestRunnerHandlerErrors(t *testing.T) {
+	fake := &fakeClient{
+		responses: []*anthropic.MessageResponse{
+			toolUseResponse(
+				anthropic.ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{}},
+				anthropic.ToolUseContentBlock{Type: "tool_use", ID: "toolu_02", Name: "get_stock_price", Input: map[string]interface{}{}},
+			),
+			endTurnResponse("Sorry, I could not get that."),
+		},
+	}
+
+	runner, err := MakeRunner(Config{
+		Client: fake,
+		Handlers: map[string]ToolHandler{
+			"get_weather": func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				return nil, errors.New("city is required")
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := runner.Run(context.Background(), newRequest())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	toolResults := result.Turns[0].ToolResults
+	failed := toolResults[0].(anthropic.ToolResultContentBlock)
+	if !failed.IsError || failed.Content != "city is required" {
+		t.Errorf("Expected handler error to be sent back with is_error, got %+v", failed)
+	}
+
+	unknown := toolResults[1].(anthropic.ToolResultContentBlock)
+	if !unknown.IsError || unknown.Content != "unknown tool: get_stock_price" {
+		t.Errorf("Expected unknown tool to be sent back with is_error, got %+v", unknown)
+	}
+}
+
+func TestRunnerMaxIterations(t *testing.T) {
+	toolUse := anthropic.ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{}}
+	fake := &fakeClient{
+		responses: []*anthropic.MessageResponse{
+			toolUseResponse(toolUse),
+			toolUseResponse(toolUse),
+			toolUseResponse(toolUse),
+		},
+	}
+
+	runner, err := MakeRunner(Config{
+		Client:        fake,
+		MaxIterations: 2,
+		Handlers: map[string]ToolHandler{
+			"get_weather": func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				return "52f", nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := runner.Run(context.Background(), newRequest())
+	if !errors.Is(err, ErrMaxIterations) {
+		t.Fatalf("Expected ErrMaxIterations, got %v", err)
+	}
+
+	if len(result.Turns) != 2 {
+		t.Errorf("Expected the transcript of 2 turns, got %d", len(result.Turns))
+	}
+}
+
+func TestMakeRunnerClientRequired(t *testing.T) {
+	_, err := MakeRunner(Config{})
+	if !errors.Is(err, ErrClientRequired) {
+		t.Errorf("Expected ErrClientRequired, got %v", err)
+	}
+}
+
+func TestRunnerRejectsInvalidToolInput(t *testing.T) {
+	fake := &fakeClient{
+		responses: []*anthropic.MessageResponse{
+			toolUseResponse(
+				anthropic.ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{"town": "Charleston"}},
+			),
+			endTurnResponse("Let me try again."),
+		},
+	}
+
+	called := false
+	runner, err := MakeRunner(Config{
+		Client: fake,
+		Handlers: map[string]ToolHandler{
+			"get_weather": func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				called = true
+				return "52f", nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := newRequest()
+	request.Tools = []anthropic.Tool{{
+		Name: "get_weather",
+		InputSchema: anthropic.InputSchema{
+			Type: "object",
+			Properties: map[string]anthropic.InputSchemaProperty{
+				"city": {Type: "string", Description: "city to get the weather for"},
+			},
+			Required: []string{"city"},
+		},
+	}}
+
+	result, err := runner.Run(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if called {
+		t.Error("Expected the handler not to be called with an invalid input")
+	}
+
+	toolResult := result.Turns[0].ToolResults[0].(anthropic.ToolResultContentBlock)
+	expected := "invalid input for tool get_weather: city: is required"
+	if !toolResult.IsError || toolResult.Content != expected {
+		t.Errorf("Expected validation error %q, got %+v", expected, toolResult)
+	}
+}
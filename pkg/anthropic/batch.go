@@ -0,0 +1,166 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxBatchRequests is the maximum number of requests in a message batch.
+	MaxBatchRequests = 100000
+	// maxBatchCustomIDLength is the maximum length of the custom_id of a batch request.
+	maxBatchCustomIDLength = 64
+)
+
+// Processing statuses of a message batch.
+const (
+	BatchStatusInProgress = "in_progress"
+	BatchStatusCanceling  = "canceling"
+	BatchStatusEnded      = "ended"
+)
+
+// Types of the result of a batch request.
+const (
+	BatchResultSucceeded = "succeeded"
+	BatchResultErrored   = "errored"
+	BatchResultCanceled  = "canceled"
+	BatchResultExpired   = "expired"
+)
+
+// BatchRequest is a single message request of a batch, identified in the results by its CustomID.
+type BatchRequest struct {
+	CustomID string          `json:"custom_id"`
+	Params   *MessageRequest `json:"params"`
+}
+
+// BatchRequestCounts counts the requests of a batch by status.
+type BatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// MessageBatch is the response from the Anthropic API describing a message batch.
+type MessageBatch struct {
+	ID                string             `json:"id"`
+	Type              string             `json:"type"`
+	ProcessingStatus  string             `json:"processing_status"`
+	RequestCounts     BatchRequestCounts `json:"request_counts"`
+	CreatedAt         time.Time          `json:"created_at"`
+	ExpiresAt         time.Time          `json:"expires_at"`
+	EndedAt           *time.Time         `json:"ended_at"`
+	CancelInitiatedAt *time.Time         `json:"cancel_initiated_at"`
+	ArchivedAt        *time.Time         `json:"archived_at"`
+	// ResultsURL is set once the batch has ended.
+	ResultsURL string `json:"results_url"`
+}
+
+// Ended reports whether the batch has finished processing and its results are available.
+func (b *MessageBatch) Ended() bool {
+	return b.ProcessingStatus == BatchStatusEnded
+}
+
+// ListBatchesOptions pages through the message batches, most recent first.
+type ListBatchesOptions struct {
+	// BeforeID returns the page of batches right before this batch id.
+	BeforeID string
+	// AfterID returns the page of batches right after this batch id.
+	AfterID string
+	// Limit is the number of batches per page, between 1 and 100 (defaults to 20).
+	Limit int
+}
+
+// MessageBatchList is a page of message batches.
+type MessageBatchList struct {
+	Data    []MessageBatch `json:"data"`
+	HasMore bool           `json:"has_more"`
+	FirstID string         `json:"first_id"`
+	LastID  string         `json:"last_id"`
+}
+
+// DeletedMessageBatch is the response from the Anthropic API for a deleted message batch.
+type DeletedMessageBatch struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// BatchResult is the outcome of a single request of a batch, read from the results file.
+type BatchResult struct {
+	CustomID string
+	// Type is one of BatchResultSucceeded, BatchResultErrored, BatchResultCanceled or
+	// BatchResultExpired.
+	Type string
+	// Message is the response of a succeeded request.
+	Message *MessageResponse
+	// Error is the error of an errored request.
+	Error *APIError
+}
+
+// UnmarshalJSON decodes a line of the results file.
+func (r *BatchResult) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		CustomID string `json:"custom_id"`
+		Result   struct {
+			Type    string           `json:"type"`
+			Message *MessageResponse `json:"message"`
+			Error   json.RawMessage  `json:"error"`
+		} `json:"result"`
+	}{}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	*r = BatchResult{
+		CustomID: raw.CustomID,
+		Type:     raw.Result.Type,
+		Message:  raw.Result.Message,
+	}
+	if len(raw.Result.Error) > 0 && string(raw.Result.Error) != "null" {
+		r.Error = NewAPIError(0, nil, raw.Result.Error)
+	}
+
+	return nil
+}
+
+// Succeeded reports whether the request of the result succeeded.
+func (r *BatchResult) Succeeded() bool {
+	return r.Type == BatchResultSucceeded
+}
+
+func ValidateBatchRequests(requests []BatchRequest) error {
+	if len(requests) == 0 {
+		return fmt.Errorf("a batch requires at least one request")
+	}
+
+	if len(requests) > MaxBatchRequests {
+		return fmt.Errorf("too many batch requests, maximum is %d", MaxBatchRequests)
+	}
+
+	customIDs := map[string]bool{}
+	for _, request := range requests {
+		if request.CustomID == "" || len(request.CustomID) > maxBatchCustomIDLength {
+			return fmt.Errorf("batch request custom_id %q must be between 1 and %d characters", request.CustomID, maxBatchCustomIDLength)
+		}
+
+		if customIDs[request.CustomID] {
+			return fmt.Errorf("duplicate batch request custom_id %q", request.CustomID)
+		}
+		customIDs[request.CustomID] = true
+
+		if request.Params == nil {
+			return fmt.Errorf("batch request %s has no params", request.CustomID)
+		}
+
+		err := ValidateMessageRequest(request.Params)
+		if err != nil {
+			return fmt.Errorf("invalid batch request %s: %w", request.CustomID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateBatchRequests(t *testing.T) {
+	params := &MessageRequest{Model: Claude35Sonnet}
+
+	testCases := []struct {
+		requests []BatchRequest
+		expErr   string
+	}{
+		{
+			requests: nil,
+			expErr:   "a batch requires at least one request",
+		},
+		{
+			requests: []BatchRequest{{CustomID: "", Params: params}},
+			expErr:   `batch request custom_id "" must be between 1 and 64 characters`,
+		},
+		{
+			requests: []BatchRequest{{CustomID: strings.Repeat("a", 65), Params: params}},
+			expErr:   `batch request custom_id "` + strings.Repeat("a", 65) + `" must be between 1 and 64 characters`,
+		},
+		{
+			requests: []BatchRequest{{CustomID: "first"}},
+			expErr:   "batch request first has no params",
+		},
+		{
+			requests: []BatchRequest{{CustomID: "first", Params: &MessageRequest{Model: Claude35Sonnet, Stream: true}}},
+			expErr:   "invalid batch request first: cannot use Message with streaming enabled, use MessageStream instead",
+		},
+		{
+			requests: []BatchRequest{{CustomID: "first", Params: params}, {CustomID: "second", Params: params}},
+		},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateBatchRequests(tc.requests)
+		if tc.expErr == "" {
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != tc.expErr {
+			t.Errorf("expected error %s, got %v", tc.expErr, err)
+		}
+	}
+}
+
+func TestBatchResultUnmarshal(t *testing.T) {
+	result := &BatchResult{}
+	err := result.UnmarshalJSON([]byte(`{"custom_id":"first","result":{"type":"errored","error":{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.CustomID != "first" || result.Type != BatchResultErrored || result.Message != nil {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if !errors.Is(result.Error, ErrAnthropicOverloaded) || result.Error.Message != "Overloaded" {
+		t.Errorf("expected an overloaded error, got %v", result.Error)
+	}
+}
@@ -0,0 +1,88 @@
+package anthropic
+
+// MaxCacheBreakpoints is the maximum number of cache_control breakpoints allowed in a request.
+const MaxCacheBreakpoints = 4
+
+const (
+	// CacheControlTypeEphemeral is the only cache type currently supported by the API.
+	CacheControlTypeEphemeral = "ephemeral"
+)
+
+// CacheControl marks the end of a prompt prefix to cache, see
+// https://docs.anthropic.com/en/docs/build-with-claude/prompt-caching
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// NewEphemeralCacheControl creates a cache_control breakpoint of the ephemeral type.
+func NewEphemeralCacheControl() *CacheControl {
+	return &CacheControl{Type: CacheControlTypeEphemeral}
+}
+
+// SetCacheControl returns block with its cache_control set. Only text, image, document and
+// tool_result blocks can be cached; other blocks are returned unchanged.
+func SetCacheControl(block ContentBlock, cacheControl *CacheControl) ContentBlock {
+	switch b := block.(type) {
+	case TextContentBlock:
+		b.CacheControl = cacheControl
+		return b
+	case ImageContentBlock:
+		b.CacheControl = cacheControl
+		return b
+	case DocumentContentBlock:
+		b.CacheControl = cacheControl
+		return b
+	case ToolResultContentBlock:
+		b.CacheControl = cacheControl
+		return b
+	}
+	return block
+}
+
+// CountCacheControl counts the cache_control breakpoints set on the tools, system blocks and content
+// blocks of the MessageRequest.
+func (m *MessageRequest) CountCacheControl() int {
+	count := 0
+	for _, block := range m.SystemBlocks {
+		if block.CacheControl != nil {
+			count++
+		}
+	}
+	for _, tool := range m.Tools {
+		if tool.CacheControl != nil {
+			count++
+		}
+	}
+	for _, message := range m.Messages {
+		count += countContentCacheControl(message.Content)
+	}
+	return count
+}
+
+func countContentCacheControl(blocks []ContentBlock) int {
+	count := 0
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case TextContentBlock:
+			if b.CacheControl != nil {
+				count++
+			}
+		case ImageContentBlock:
+			if b.CacheControl != nil {
+				count++
+			}
+		case DocumentContentBlock:
+			if b.CacheControl != nil {
+				count++
+			}
+		case ToolResultContentBlock:
+			if b.CacheControl != nil {
+				count++
+			}
+			if nested, ok := b.Content.([]ContentBlock); ok {
+				count += countContentCacheControl(nested)
+			}
+		}
+	}
+	return count
+}
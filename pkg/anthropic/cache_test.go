@@ -0,0 +1,99 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCacheControlMarshalling(t *testing.T) {
+	block := SetCacheControl(NewTextContentBlock("a long document"), NewEphemeralCacheControl())
+
+	data, err := json.Marshal(block)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"type":"text","text":"a long document","cache_control":{"type":"ephemeral"}}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	data, err = json.Marshal(NewTextContentBlock("uncached"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected = `{"type":"text","text":"uncached"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestCountCacheControl(t *testing.T) {
+	cached := NewEphemeralCacheControl()
+
+	request := &MessageRequest{
+		Model: Claude35Sonnet,
+		Tools: []Tool{
+			{Name: "first"},
+			{Name: "second", CacheControl: cached},
+		},
+		Messages: []MessagePartRequest{
+			{
+				Role: "user",
+				Content: []ContentBlock{
+					SetCacheControl(NewTextContentBlock("context"), cached),
+					NewTextContentBlock("question"),
+				},
+			},
+			{
+				Role: "user",
+				Content: []ContentBlock{
+					SetCacheControl(NewToolResultContentBlock("tool-1", []ContentBlock{
+						SetCacheControl(NewTextContentBlock("nested"), cached),
+					}, false), cached),
+				},
+			},
+		},
+	}
+
+	if count := request.CountCacheControl(); count != 4 {
+		t.Errorf("Expected 4 cache_control breakpoints, got %d", count)
+	}
+
+	err := ValidateMessageRequest(request)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	request.Tools[0].CacheControl = cached
+	err = ValidateMessageRequest(request)
+	if err == nil || err.Error() != "too many cache_control breakpoints, maximum is 4" {
+		t.Errorf("Expected too many breakpoints error, got %v", err)
+	}
+}
+
+func TestCacheUsageUnmarshalling(t *testing.T) {
+	data := `{
+		"id": "msg_1",
+		"type": "message",
+		"role": "assistant",
+		"content": [{"type": "text", "text": "Hi"}],
+		"usage": {
+			"input_tokens": 10,
+			"output_tokens": 5,
+			"cache_creation_input_tokens": 1500,
+			"cache_read_input_tokens": 300
+		}
+	}`
+
+	response := &MessageResponse{}
+	err := json.Unmarshal([]byte(data), response)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if response.Usage.CacheCreationInputTokens != 1500 || response.Usage.CacheReadInputTokens != 300 {
+		t.Errorf("Unexpected cache usage: %+v", response.Usage)
+	}
+}
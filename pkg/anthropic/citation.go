@@ -0,0 +1,58 @@
+package anthropic
+
+import "encoding/json"
+
+// Types of citations.
+const (
+	CitationTypeCharLocation         = "char_location"
+	CitationTypePageLocation         = "page_location"
+	CitationTypeContentBlockLocation = "content_block_location"
+)
+
+// Citation points to the part of a document supporting a text block of the response. The location
+// fields set depend on the type of the citation:
+//   - char_location (plain text documents): StartCharIndex and EndCharIndex
+//   - page_location (PDF documents): StartPageNumber and EndPageNumber
+//   - content_block_location (custom content documents): StartBlockIndex and EndBlockIndex
+//
+// End indexes are exclusive.
+type Citation struct {
+	Type          string `json:"type"`
+	CitedText     string `json:"cited_text"`
+	DocumentIndex int    `json:"document_index"`
+	DocumentTitle string `json:"document_title"`
+
+	StartCharIndex  int `json:"start_char_index"`
+	EndCharIndex    int `json:"end_char_index"`
+	StartPageNumber int `json:"start_page_number"`
+	EndPageNumber   int `json:"end_page_number"`
+	StartBlockIndex int `json:"start_block_index"`
+	EndBlockIndex   int `json:"end_block_index"`
+}
+
+// MarshalJSON encodes the citation with the location fields of its type only, so it can be sent
+// back to the API in the next turn.
+func (c Citation) MarshalJSON() ([]byte, error) {
+	citation := map[string]interface{}{
+		"type":           c.Type,
+		"cited_text":     c.CitedText,
+		"document_index": c.DocumentIndex,
+	}
+	if c.DocumentTitle != "" {
+		citation["document_title"] = c.DocumentTitle
+	}
+
+	switch c.Type {
+	case CitationTypeCharLocation:
+		citation["start_char_index"] = c.StartCharIndex
+		citation["end_char_index"] = c.EndCharIndex
+	case CitationTypePageLocation:
+		citation["start_page_number"] = c.StartPageNumber
+		citation["end_page_number"] = c.EndPageNumber
+	case CitationTypeContentBlockLocation:
+		citation["start_block_index"] = c.StartBlockIndex
+		citation["end_block_index"] = c.EndBlockIndex
+	}
+
+	return json.Marshal(citation)
+}
@@ -2,20 +2,28 @@ package bedrock
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"regexp"
-	"strconv"
+	"log/slog"
+	"net/http"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 const (
 	AnthropicVersion = "bedrock-2023-05-31"
 
+	BedrockModelClaude37Sonnet          = "anthropic.claude-3-7-sonnet-20250219-v1:0"
+	BedrockModelClaude37Sonnet_20250219 = "anthropic.claude-3-7-sonnet-20250219-v1:0"
 	BedrockModelClaude35Sonnet          = "anthropic.claude-3-5-sonnet-20241022-v2:0"
 	BedrockModelClaude35Sonnet_20241022 = "anthropic.claude-3-5-sonnet-20241022-v2:0"
 	BedrockModelClaude35Sonnet_20240620 = "anthropic.claude-3-5-sonnet-20240620-v1:0"
@@ -34,6 +42,8 @@ const (
 type Client struct {
 	brCli             *bedrockruntime.Client
 	crInferenceRegion string
+	retryPolicy       *anthropic.RetryPolicy
+	logger            invocationLogger
 }
 
 type Config struct {
@@ -42,6 +52,18 @@ type Config struct {
 	SecretAccessKey      string
 	SessionToken         string
 	CrossRegionInference bool
+	// Optional (requests are not retried when nil), e.g. anthropic.DefaultRetryPolicy(). When set,
+	// it replaces the retries of the AWS SDK.
+	RetryPolicy *anthropic.RetryPolicy
+	// Optional (nothing is logged when nil). Invocations and responses are logged at debug level,
+	// retries at warn level.
+	Logger *slog.Logger
+	// LogBodies includes the request and response bodies in the logs. It is off by default, as
+	// bodies contain the full prompts and completions.
+	LogBodies bool
+	// Optional (defaults to DefaultLogBodyLimit) number of bytes of a body logged, -1 logs bodies
+	// in full.
+	LogBodyLimit int
 }
 
 func MakeClient(ctx context.Context, cfg Config) (*Client, error) {
@@ -49,21 +71,25 @@ func MakeClient(ctx context.Context, cfg Config) (*Client, error) {
 		return nil, fmt.Errorf("region is requried for establishing anthropic bedrock client")
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(
-		ctx,
+	loadOptions := []func(*config.LoadOptions) error{
 		config.WithRegion(cfg.Region),
-	)
+	}
 
 	// override config load with static credentials if provided
 	if cfg.AccessKeyID != "" && cfg.SecretAccessKey != "" {
 		credsProvider := credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)
-		awsCfg, err = config.LoadDefaultConfig(
-			ctx,
-			config.WithRegion(cfg.Region),
-			config.WithCredentialsProvider(credsProvider),
-		)
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(credsProvider))
+	}
+
+	// retries are handled by the retry policy instead of the SDK, so they aren't compounded
+	if cfg.RetryPolicy != nil {
+		loadOptions = append(loadOptions, config.WithRetryer(func() aws.Retryer {
+			return aws.NopRetryer{}
+		}))
 	}
 
+	awsCfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
+
 	if err != nil {
 		return nil, err
 	}
@@ -82,48 +108,39 @@ func MakeClient(ctx context.Context, cfg Config) (*Client, error) {
 		}
 	}
 
+	if cfg.LogBodyLimit == 0 {
+		cfg.LogBodyLimit = DefaultLogBodyLimit
+	}
+
 	return &Client{
 		brCli:             bedrockruntime.NewFromConfig(awsCfg),
 		crInferenceRegion: regionPrefix,
+		retryPolicy:       cfg.RetryPolicy,
+		logger: invocationLogger{
+			logger:    cfg.Logger,
+			logBodies: cfg.LogBodies,
+			bodyLimit: cfg.LogBodyLimit,
+		},
 	}, nil
 }
 
-// adaptModelForMessage takes the model as defined in anthropic.Model and adapts it to the model Bedrock expects
+// adaptModelForMessage takes the model as defined in anthropic.Model and adapts it to the model Bedrock expects,
+// using the Bedrock model ID and cross-region availability of the model registry.
 func (c *Client) adaptModelForMessage(model anthropic.Model) (string, error) {
-	adaptedModel := ""
-
-	switch model {
-	case anthropic.Claude35Sonnet:
-		adaptedModel = BedrockModelClaude35Sonnet
-	case anthropic.Claude35Sonnet_20241022:
-		adaptedModel = BedrockModelClaude35Sonnet_20241022
-	case anthropic.Claude35Sonnet_20240620:
-		adaptedModel = BedrockModelClaude35Sonnet_20240620
-	case anthropic.Claude35Haiku:
-		adaptedModel = BedrockModelClaude35Haiku
-	case anthropic.Claude35Haiku_20241022:
-		adaptedModel = BedrockModelClaude35Haiku_20241022
-	case anthropic.Claude3Opus:
-		adaptedModel = BedrockModelClaude3Opus
-	case anthropic.Claude3Sonnet:
-		adaptedModel = BedrockModelClaude3Sonnet
-	case anthropic.Claude3Haiku:
-		adaptedModel = BedrockModelClaude3Haiku
-	case anthropic.ClaudeV2_1:
-		adaptedModel = BedrockModelClaudeV2_1
-	default:
+	capabilities, _ := model.Capabilities()
+	if !capabilities.Message || capabilities.BedrockModelID == "" {
 		return "", fmt.Errorf("model %s is not compatible with the bedrock message endpoint", model)
 	}
 
 	if c.crInferenceRegion == "" {
-		return adaptedModel, nil
+		return capabilities.BedrockModelID, nil
 	}
 
-	if adaptedModel == BedrockModelClaudeV2_1 {
-		return "", fmt.Errorf("bedrock model %s is not compatible with cross-region inference", adaptedModel)
+	if !capabilities.BedrockCrossRegion {
+		return "", fmt.Errorf("bedrock model %s is not compatible with cross-region inference", capabilities.BedrockModelID)
 	}
 
-	return fmt.Sprintf("%s.%s", c.crInferenceRegion, adaptedModel), nil
+	return fmt.Sprintf("%s.%s", c.crInferenceRegion, capabilities.BedrockModelID), nil
 }
 
 // MessageRequest is an override for the default message request to adapt the request for the Bedrock API.
@@ -134,6 +151,40 @@ type MessageRequest struct {
 	Stream           bool   `json:"stream,omitempty"` // shadow for Stream
 }
 
+// MarshalJSON encodes the request the way Bedrock expects it: the model is given by the model ID of
+// the invocation and streaming by the operation used, so both are left out of the body, and the
+// beta features required by the request are listed in anthropic_beta.
+func (r MessageRequest) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(r.MessageRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &body)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(body, "model")
+	delete(body, "stream")
+
+	body["anthropic_version"], err = json.Marshal(r.AnthropicVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// beta features are enabled in the body on Bedrock
+	if betas := r.MessageRequest.ComputerUseBetas(); len(betas) > 0 {
+		body["anthropic_beta"], err = json.Marshal(betas)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(body)
+}
+
 func adaptMessageRequest(req *anthropic.MessageRequest) *MessageRequest {
 	return &MessageRequest{
 		MessageRequest:   *req,
@@ -141,17 +192,69 @@ func adaptMessageRequest(req *anthropic.MessageRequest) *MessageRequest {
 	}
 }
 
-func extractErrStatusCode(err error) int {
-	re := regexp.MustCompile(`StatusCode: (\d+)`)
-	match := re.FindStringSubmatch(err.Error())
+// retry reports whether the failed attempt should be retried, after waiting for the retry delay.
+func (c *Client) retry(ctx context.Context, attempt int, err error) bool {
+	statusCode, header := responseStatus(err)
+	if !c.retryPolicy.ShouldRetry(attempt, statusCode, err) {
+		return false
+	}
 
-	if len(match) > 1 {
-		res, err := strconv.Atoi(match[1])
-		if err != nil {
-			return 0
+	c.logger.logRetry(ctx, attempt, err)
+	return c.retryPolicy.Wait(ctx, attempt, header) == nil
+}
+
+// responseStatus returns the status code and headers of the HTTP response that caused err, if any.
+func responseStatus(err error) (int, http.Header) {
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) && responseErr.Response != nil && responseErr.Response.Response != nil {
+		return responseErr.HTTPStatusCode(), responseErr.Response.Header
+	}
+	return 0, nil
+}
+
+// exceptionErrorTypes maps the exceptions of Bedrock to the equivalent Anthropic error types, so
+// errors without a status code, e.g. on a stream, match the ErrAnthropic* sentinels.
+var exceptionErrorTypes = map[string]string{
+	"ValidationException":         "invalid_request_error",
+	"UnrecognizedClientException": "authentication_error",
+	"ExpiredTokenException":       "authentication_error",
+	"AccessDeniedException":       "permission_error",
+	"ResourceNotFoundException":   "not_found_error",
+	"ThrottlingException":         "rate_limit_error",
+	"InternalServerException":     "api_error",
+	"ModelStreamErrorException":   "api_error",
+	"ModelErrorException":         "api_error",
+	"ModelTimeoutException":       "api_error",
+	"ServiceUnavailableException": "overloaded_error",
+	"ModelNotReadyException":      "overloaded_error",
+}
+
+// adaptError converts an error returned by Bedrock to an *anthropic.APIError when it carries a
+// service error, so callers can handle both clients' errors the same way. Known exceptions are
+// given the equivalent Anthropic error type, the exception name is kept in the message.
+func adaptError(err error) error {
+	apiErr := &anthropic.APIError{}
+
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		apiErr.RequestID = responseErr.ServiceRequestID()
+		if responseErr.Response != nil {
+			apiErr.StatusCode = responseErr.HTTPStatusCode()
 		}
-		return res
 	}
 
-	return 0
+	var serviceErr smithy.APIError
+	if errors.As(err, &serviceErr) {
+		apiErr.Type = serviceErr.ErrorCode()
+		apiErr.Message = serviceErr.ErrorMessage()
+		if errorType, ok := exceptionErrorTypes[serviceErr.ErrorCode()]; ok {
+			apiErr.Type = errorType
+			apiErr.Message = fmt.Sprintf("%s: %s", serviceErr.ErrorCode(), serviceErr.ErrorMessage())
+		}
+	}
+
+	if apiErr.StatusCode == 0 && apiErr.Type == "" {
+		return err
+	}
+	return apiErr
 }
@@ -2,11 +2,18 @@ package bedrock
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 func Test_Client_Success_RegionOnly(t *testing.T) {
@@ -96,6 +103,10 @@ func Test_adaptModelForMessage_Success_NonCrossRegion(t *testing.T) {
 			modelInput:          anthropic.Claude3Haiku,
 			expectedModelOutput: BedrockModelClaude3Haiku,
 		},
+		{
+			modelInput:          anthropic.Claude35Haiku,
+			expectedModelOutput: BedrockModelClaude35Haiku,
+		},
 		{
 			modelInput:          anthropic.ClaudeV2_1,
 			expectedModelOutput: BedrockModelClaudeV2_1,
@@ -197,6 +208,32 @@ func Test_adaptModelForMessage_Failure_ClaudeV2_CrossRegionInference(t *testing.
 	}
 }
 
+func Test_adaptModelForMessage_Success_RegisteredModel(t *testing.T) {
+	client, err := MakeClient(context.Background(), Config{
+		Region:               "us-west-2",
+		CrossRegionInference: true,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error when establishing client %s", err.Error())
+	}
+
+	model := anthropic.Model("claude-bedrock-registered")
+	anthropic.RegisterModel(model, anthropic.ModelCapabilities{
+		Message:            true,
+		BedrockModelID:     "anthropic.claude-bedrock-registered-v1:0",
+		BedrockCrossRegion: true,
+	})
+
+	result, err := client.adaptModelForMessage(model)
+	if err != nil {
+		t.Errorf("Unexpected error when adapting model: %s", err.Error())
+	}
+
+	if result != "us.anthropic.claude-bedrock-registered-v1:0" {
+		t.Errorf("Error when adapting model. Expected: us.anthropic.claude-bedrock-registered-v1:0, Actual: %s", result)
+	}
+}
+
 func assertSuccessClient(t *testing.T, client *Client, err error, crRegionValue string) {
 	if err != nil {
 		t.Errorf("Unexpected error %s", err.Error())
@@ -210,3 +247,119 @@ func assertSuccessClient(t *testing.T, client *Client, err error, crRegionValue
 		t.Errorf("Unexpected value for inference region %s", client.crInferenceRegion)
 	}
 }
+
+func Test_AdaptMessageRequest_SystemBlocks(t *testing.T) {
+	req := anthropic.NewMessageRequest(
+		anthropic.WithMessageModel(anthropic.Claude35Sonnet),
+		anthropic.WithMessageMaxTokens(10),
+		anthropic.WithMessageStream(true),
+		anthropic.WithSystemBlocks(anthropic.TextContentBlock{
+			Type:         "text",
+			Text:         "You are a helpful assistant.",
+			CacheControl: anthropic.NewEphemeralCacheControl(),
+		}),
+	)
+	req.AddUserMessage(anthropic.NewTextContentBlock("Hello"))
+
+	data, err := json.Marshal(adaptMessageRequest(req))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"anthropic_version":"bedrock-2023-05-31","max_tokens":10,"messages":[{"role":"user","content":[{"type":"text","text":"Hello"}]}],"system":[{"type":"text","text":"You are a helpful assistant.","cache_control":{"type":"ephemeral"}}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func Test_AdaptError_StreamException(t *testing.T) {
+	policy := &anthropic.RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		code      string
+		sentinel  error
+		retryable bool
+	}{
+		{"ThrottlingException", anthropic.ErrAnthropicRateLimit, true},
+		{"ServiceUnavailableException", anthropic.ErrAnthropicOverloaded, true},
+		{"ModelStreamErrorException", anthropic.ErrAnthropicInternalServer, true},
+		{"ValidationException", anthropic.ErrAnthropicInvalidRequest, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			// exceptions received on a stream have no HTTP response
+			err := fmt.Errorf("error reading from stream: %w", adaptError(&smithy.GenericAPIError{Code: test.code, Message: "failed"}))
+
+			if !errors.Is(err, test.sentinel) {
+				t.Errorf("Expected %v to match %v", err, test.sentinel)
+			}
+
+			if policy.ShouldRetry(1, 0, err) != test.retryable {
+				t.Errorf("Expected retryable %v for %v", test.retryable, err)
+			}
+		})
+	}
+
+	var apiErr *anthropic.APIError
+	unknown := adaptError(&smithy.GenericAPIError{Code: "SomeNewException", Message: "failed"})
+	if !errors.As(unknown, &apiErr) || apiErr.Type != "SomeNewException" || apiErr.Message != "failed" {
+		t.Errorf("Expected unknown exceptions to keep their code, got %+v", unknown)
+	}
+}
+
+func Test_AdaptMessageRequest_ComputerUse(t *testing.T) {
+	req := anthropic.NewMessageRequest(
+		anthropic.WithMessageModel(anthropic.Claude35Sonnet),
+		anthropic.WithMessageMaxTokens(10),
+	)
+	req.Tools = []anthropic.Tool{anthropic.NewBashTool()}
+	req.AddUserMessage(anthropic.NewTextContentBlock("List the files"))
+
+	data, err := json.Marshal(adaptMessageRequest(req))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"anthropic_beta":["computer-use-2024-10-22"],"anthropic_version":"bedrock-2023-05-31","max_tokens":10,"messages":[{"role":"user","content":[{"type":"text","text":"List the files"}]}],"tools":[{"type":"bash_20241022","name":"bash"}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func Test_AdaptError(t *testing.T) {
+	err := &smithy.OperationError{
+		ServiceID:     "Bedrock Runtime",
+		OperationName: "InvokeModel",
+		Err: &awshttp.ResponseError{
+			ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests}},
+				Err: &smithy.GenericAPIError{
+					Code:    "ThrottlingException",
+					Message: "Too many requests, please wait before trying again.",
+				},
+			},
+			RequestID: "7f1c2d3e",
+		},
+	}
+
+	adapted := adaptError(err)
+	if !errors.Is(adapted, anthropic.ErrAnthropicRateLimit) {
+		t.Fatalf("Expected rate limit error, got %v", adapted)
+	}
+
+	var apiErr *anthropic.APIError
+	if !errors.As(adapted, &apiErr) {
+		t.Fatalf("Expected an APIError, got %T", adapted)
+	}
+
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Type != "rate_limit_error" ||
+		apiErr.Message != "ThrottlingException: Too many requests, please wait before trying again." || apiErr.RequestID != "7f1c2d3e" {
+		t.Errorf("Unexpected APIError %+v", apiErr)
+	}
+
+	plain := fmt.Errorf("dial tcp: connection refused")
+	if adaptError(plain) != plain {
+		t.Errorf("Expected errors without a service error to be returned unchanged")
+	}
+}
@@ -0,0 +1,14 @@
+package bedrock
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+// CountTokens is not supported, Bedrock doesn't expose the count tokens endpoint. It returns an
+// error matching anthropic.ErrCountTokensUnsupported.
+func (c *Client) CountTokens(ctx context.Context, req *anthropic.MessageRequest) (int, error) {
+	return 0, fmt.Errorf("error counting tokens with bedrock: %w", anthropic.ErrCountTokensUnsupported)
+}
@@ -0,0 +1,64 @@
+package bedrock
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/utils"
+)
+
+// DefaultLogBodyLimit is the number of bytes of a request or response body logged when LogBodies
+// is enabled and no limit is configured.
+const DefaultLogBodyLimit = 4096
+
+// invocationLogger logs the model invocations of the client; a nil logger logs nothing. AWS
+// credentials are never logged, the SDK signs the requests after they leave the client.
+type invocationLogger struct {
+	logger    *slog.Logger
+	logBodies bool
+	bodyLimit int
+}
+
+func (l invocationLogger) logInvocation(ctx context.Context, modelID string, body []byte, attempt int) {
+	if l.logger == nil || !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("model_id", modelID),
+		slog.Int("attempt", attempt),
+	}
+	if l.logBodies {
+		attrs = append(attrs, slog.String("body", utils.Truncate(string(body), l.bodyLimit)))
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "invoking model", attrs...)
+}
+
+// logResponse logs a response received after duration, body is nil for streams.
+func (l invocationLogger) logResponse(ctx context.Context, body []byte, duration time.Duration) {
+	if l.logger == nil || !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Duration("duration", duration),
+	}
+	if l.logBodies && body != nil {
+		attrs = append(attrs, slog.String("body", utils.Truncate(string(body), l.bodyLimit)))
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "received response", attrs...)
+}
+
+func (l invocationLogger) logRetry(ctx context.Context, attempt int, err error) {
+	if l.logger == nil {
+		return
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelWarn, "retrying request",
+		slog.Int("attempt", attempt),
+		slog.String("error", err.Error()),
+	)
+}
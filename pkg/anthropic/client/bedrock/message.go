@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 )
 
@@ -34,15 +36,24 @@ func (c *Client) sendMessageRequest(ctx context.Context, req *anthropic.MessageR
 		return nil, fmt.Errorf("error marshalling message request: %w", err)
 	}
 
-	response, err := c.brCli.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-		Body:        data,
-		ModelId:     aws.String(adaptedModel),
-		ContentType: aws.String("application/json"),
-	})
+	var response *bedrockruntime.InvokeModelOutput
+	for attempt := 1; ; attempt++ {
+		c.logger.logInvocation(ctx, adaptedModel, data, attempt)
+		start := time.Now()
 
-	if err != nil {
-		errStatusCode := extractErrStatusCode(err)
-		return nil, anthropic.MapHTTPStatusCodeToError(errStatusCode)
+		response, err = c.brCli.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+			Body:        data,
+			ModelId:     aws.String(adaptedModel),
+			ContentType: aws.String("application/json"),
+		})
+		if err == nil {
+			c.logger.logResponse(ctx, response.Body, time.Since(start))
+			break
+		}
+
+		if !c.retry(ctx, attempt, err) {
+			return nil, fmt.Errorf("error invoking model: %w", adaptError(err))
+		}
 	}
 
 	msgResp := &anthropic.MessageResponse{}
@@ -50,6 +61,7 @@ func (c *Client) sendMessageRequest(ctx context.Context, req *anthropic.MessageR
 	if err != nil {
 		return nil, fmt.Errorf("error unmarshalling message response: %w", err)
 	}
+	msgResp.RequestID, _ = awsmiddleware.GetRequestIDMetadata(response.ResultMetadata)
 
 	return msgResp, nil
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
 )
@@ -52,24 +54,62 @@ func (c *Client) handleMessageStreaming(
 		return
 	}
 
-	response, err := c.brCli.InvokeModelWithResponseStream(
-		ctx,
-		&bedrockruntime.InvokeModelWithResponseStreamInput{
-			Body:        data,
-			ModelId:     aws.String(adaptedModel),
-			ContentType: aws.String("application/json"),
-		},
-	)
-	if err != nil {
-		errStatusCode := extractErrStatusCode(err)
-		errCh <- anthropic.MapHTTPStatusCodeToError(errStatusCode)
-		return
+	// a stream that fails before delivering any event is retried as a whole, once events have been
+	// delivered the error is returned to the caller
+	for attempt := 1; ; attempt++ {
+		c.logger.logInvocation(ctx, adaptedModel, data, attempt)
+		start := time.Now()
+
+		response, err := c.brCli.InvokeModelWithResponseStream(
+			ctx,
+			&bedrockruntime.InvokeModelWithResponseStreamInput{
+				Body:        data,
+				ModelId:     aws.String(adaptedModel),
+				ContentType: aws.String("application/json"),
+			},
+		)
+		if err != nil {
+			if c.retry(ctx, attempt, err) {
+				continue
+			}
+
+			errCh <- fmt.Errorf("error invoking model: %w", adaptError(err))
+			return
+		}
+
+		c.logger.logResponse(ctx, nil, time.Since(start))
+
+		requestID, _ := awsmiddleware.GetRequestIDMetadata(response.ResultMetadata)
+		delivered, err := c.processMessageStream(ctx, response.GetStream(), requestID, msCh)
+		if err == nil {
+			return
+		}
+
+		if delivered > 0 || !c.retry(ctx, attempt, err) {
+			errCh <- err
+			return
+		}
 	}
+}
 
-	for event := range response.GetStream().Events() {
+// processMessageStream forwards the events read from the stream, setting requestID on the
+// message_start event, and returns how many were delivered. It stops when ctx is done, so a
+// consumer that stopped reading doesn't block it.
+func (c *Client) processMessageStream(
+	ctx context.Context,
+	stream *bedrockruntime.InvokeModelWithResponseStreamEventStream,
+	requestID string,
+	msCh chan<- *anthropic.MessageStreamResponse,
+) (int, error) {
+	defer stream.Close()
+
+	delivered := 0
+	parser := anthropic.NewMessageEventParser()
+
+	for event := range stream.Events() {
 		select {
 		case <-ctx.Done():
-			return
+			return delivered, ctx.Err()
 		default:
 		}
 
@@ -77,10 +117,9 @@ func (c *Client) handleMessageStreaming(
 			event := &anthropic.MessageEvent{}
 			err := json.Unmarshal(v.Value.Bytes, event)
 			if err != nil {
-				errCh <- fmt.Errorf("error decoding event data: %w", err)
-				return
+				return delivered, fmt.Errorf("error decoding event data: %w", err)
 			}
-			msg, err := anthropic.ParseMessageEvent(
+			msg, err := parser.Parse(
 				anthropic.MessageEventType(event.Type),
 				string(v.Value.Bytes),
 			)
@@ -88,12 +127,26 @@ func (c *Client) handleMessageStreaming(
 				if _, ok := err.(anthropic.UnsupportedEventType); ok {
 					// ignore unsupported event types
 				} else {
-					errCh <- fmt.Errorf("error processing message stream: %v", err)
-					return
+					return delivered, fmt.Errorf("error processing message stream: %w", err)
 				}
 			}
 
-			msCh <- msg
+			if msg.Type == string(anthropic.MessageEventTypeMessageStart) {
+				msg.RequestID = requestID
+			}
+
+			select {
+			case msCh <- msg:
+			case <-ctx.Done():
+				return delivered, ctx.Err()
+			}
+			delivered++
 		}
 	}
+
+	if err := stream.Err(); err != nil {
+		return delivered, fmt.Errorf("error reading from stream: %w", adaptError(err))
+	}
+
+	return delivered, nil
 }
@@ -15,6 +15,9 @@ type ClientType string
 type Client interface {
 	Message(context.Context, *anthropic.MessageRequest) (*anthropic.MessageResponse, error)
 	MessageStream(context.Context, *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error)
+	// CountTokens returns the number of input tokens the request would use. Clients whose backend
+	// can't count tokens return an error matching anthropic.ErrCountTokensUnsupported.
+	CountTokens(context.Context, *anthropic.MessageRequest) (int, error)
 }
 
 func MakeClient(ctx context.Context, config interface{}) (Client, error) {
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+// MessageFunc has the signature of Client.Message.
+type MessageFunc func(context.Context, *anthropic.MessageRequest) (*anthropic.MessageResponse, error)
+
+// MessageStreamFunc has the signature of Client.MessageStream.
+type MessageStreamFunc func(context.Context, *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error)
+
+// Middleware wraps the calls made to a Client. A middleware can modify the request before calling
+// next, inspect or replace what next returns, or return without calling next at all.
+type Middleware interface {
+	WrapMessage(next MessageFunc) MessageFunc
+	WrapMessageStream(next MessageStreamFunc) MessageStreamFunc
+}
+
+// MessageMiddleware creates a Middleware wrapping Message only, MessageStream calls pass through.
+func MessageMiddleware(wrap func(next MessageFunc) MessageFunc) Middleware {
+	return middlewareFuncs{wrapMessage: wrap}
+}
+
+// MessageStreamMiddleware creates a Middleware wrapping MessageStream only, Message calls pass
+// through.
+func MessageStreamMiddleware(wrap func(next MessageStreamFunc) MessageStreamFunc) Middleware {
+	return middlewareFuncs{wrapMessageStream: wrap}
+}
+
+type middlewareFuncs struct {
+	wrapMessage       func(next MessageFunc) MessageFunc
+	wrapMessageStream func(next MessageStreamFunc) MessageStreamFunc
+}
+
+func (m middlewareFuncs) WrapMessage(next MessageFunc) MessageFunc {
+	if m.wrapMessage == nil {
+		return next
+	}
+	return m.wrapMessage(next)
+}
+
+func (m middlewareFuncs) WrapMessageStream(next MessageStreamFunc) MessageStreamFunc {
+	if m.wrapMessageStream == nil {
+		return next
+	}
+	return m.wrapMessageStream(next)
+}
+
+// Wrap returns a Client sending its calls through middlewares before they reach c. The first
+// middleware is the outermost one: it sees the request first and the response last. CountTokens
+// calls are passed to c directly.
+func Wrap(c Client, middlewares ...Middleware) Client {
+	wrapped := &wrappedClient{
+		message:       c.Message,
+		messageStream: c.MessageStream,
+		countTokens:   c.CountTokens,
+	}
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		wrapped.message = middlewares[i].WrapMessage(wrapped.message)
+		wrapped.messageStream = middlewares[i].WrapMessageStream(wrapped.messageStream)
+	}
+
+	return wrapped
+}
+
+type wrappedClient struct {
+	message       MessageFunc
+	messageStream MessageStreamFunc
+	countTokens   func(context.Context, *anthropic.MessageRequest) (int, error)
+}
+
+func (w *wrappedClient) Message(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+	return w.message(ctx, req)
+}
+
+func (w *wrappedClient) MessageStream(ctx context.Context, req *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+	return w.messageStream(ctx, req)
+}
+
+func (w *wrappedClient) CountTokens(ctx context.Context, req *anthropic.MessageRequest) (int, error) {
+	return w.countTokens(ctx, req)
+}
+
+// ErrorStream returns the channels of a stream failing with err, for middlewares short-circuiting
+// MessageStream.
+func ErrorStream(err error) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+	msCh := make(chan *anthropic.MessageStreamResponse)
+	errCh := make(chan error, 1)
+
+	errCh <- err
+	close(msCh)
+	close(errCh)
+
+	return msCh, errCh
+}
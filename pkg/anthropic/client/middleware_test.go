@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+type fakeClient struct {
+	requests []*anthropic.MessageRequest
+}
+
+func (f *fakeClient) Message(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+	f.requests = append(f.requests, req)
+	return &anthropic.MessageResponse{Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("from client")}}, nil
+}
+
+func (f *fakeClient) MessageStream(ctx context.Context, req *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+	f.requests = append(f.requests, req)
+
+	msCh := make(chan *anthropic.MessageStreamResponse, 1)
+	errCh := make(chan error)
+	msCh <- &anthropic.MessageStreamResponse{Type: "message_stop"}
+	close(msCh)
+	close(errCh)
+	return msCh, errCh
+}
+
+func (f *fakeClient) CountTokens(ctx context.Context, req *anthropic.MessageRequest) (int, error) {
+	f.requests = append(f.requests, req)
+	return 7, nil
+}
+
+// recordingMiddleware appends its name to the order when called and to the request's stop sequences.
+func recordingMiddleware(name string, order *[]string) Middleware {
+	return MessageMiddleware(func(next MessageFunc) MessageFunc {
+		return func(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+			*order = append(*order, name)
+			req.StopSequences = append(req.StopSequences, name)
+			return next(ctx, req)
+		}
+	})
+}
+
+func TestWrapMessageOrder(t *testing.T) {
+	fake := &fakeClient{}
+	order := []string{}
+
+	c := Wrap(fake, recordingMiddleware("first", &order), recordingMiddleware("second", &order))
+
+	response, err := c.Message(context.Background(), &anthropic.MessageRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Text() != "from client" {
+		t.Errorf("expected the client's response, got %q", response.Text())
+	}
+
+	expected := []string{"first", "second"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected order %v, got %v", expected, order)
+	}
+
+	if len(fake.requests) != 1 || !reflect.DeepEqual(fake.requests[0].StopSequences, expected) {
+		t.Errorf("expected the modified request to reach the client, got %+v", fake.requests)
+	}
+}
+
+func TestWrapMessageShortCircuit(t *testing.T) {
+	fake := &fakeClient{}
+	errBlocked := errors.New("blocked by policy")
+
+	c := Wrap(fake, MessageMiddleware(func(next MessageFunc) MessageFunc {
+		return func(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+			return nil, errBlocked
+		}
+	}))
+
+	_, err := c.Message(context.Background(), &anthropic.MessageRequest{})
+	if !errors.Is(err, errBlocked) {
+		t.Errorf("expected %v, got %v", errBlocked, err)
+	}
+
+	if len(fake.requests) != 0 {
+		t.Errorf("expected the client not to be called, got %d calls", len(fake.requests))
+	}
+
+	// the middleware only wraps Message
+	msCh, errCh := c.MessageStream(context.Background(), &anthropic.MessageRequest{})
+	event := <-msCh
+	if event == nil || event.Type != "message_stop" || <-errCh != nil {
+		t.Errorf("expected the stream to pass through, got %+v", event)
+	}
+}
+
+func TestWrapMessageStream(t *testing.T) {
+	fake := &fakeClient{}
+	errBlocked := errors.New("blocked by policy")
+
+	c := Wrap(fake, MessageStreamMiddleware(func(next MessageStreamFunc) MessageStreamFunc {
+		return func(ctx context.Context, req *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+			if req.Model == "" {
+				return ErrorStream(errBlocked)
+			}
+			return next(ctx, req)
+		}
+	}))
+
+	msCh, errCh := c.MessageStream(context.Background(), &anthropic.MessageRequest{})
+	if _, ok := <-msCh; ok {
+		t.Error("expected no events")
+	}
+	if err := <-errCh; !errors.Is(err, errBlocked) {
+		t.Errorf("expected %v, got %v", errBlocked, err)
+	}
+
+	msCh, _ = c.MessageStream(context.Background(), &anthropic.MessageRequest{Model: anthropic.Claude35Sonnet})
+	if event := <-msCh; event == nil || event.Type != "message_stop" {
+		t.Errorf("expected the client's stream, got %+v", event)
+	}
+}
+
+func TestWrapCountTokens(t *testing.T) {
+	fake := &fakeClient{}
+	order := []string{}
+
+	c := Wrap(fake, recordingMiddleware("outer", &order))
+
+	tokens, err := c.CountTokens(context.Background(), &anthropic.MessageRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tokens != 7 || len(fake.requests) != 1 {
+		t.Errorf("expected the call to reach the client, got %d tokens and %d requests", tokens, len(fake.requests))
+	}
+
+	if len(order) != 0 {
+		t.Errorf("expected the middlewares to be skipped, got %v", order)
+	}
+}
@@ -0,0 +1,239 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+const (
+	DefaultBatchPollInterval    = 10 * time.Second
+	DefaultBatchMaxPollInterval = 5 * time.Minute
+)
+
+// BatchPollPolicy configures how WaitForBatch polls a batch. The interval starts at Interval and
+// doubles after every poll up to MaxInterval. Zero fields fall back to their defaults.
+type BatchPollPolicy struct {
+	Interval    time.Duration
+	MaxInterval time.Duration
+}
+
+// CreateBatch creates a message batch processing requests asynchronously.
+func (c *Client) CreateBatch(ctx context.Context, requests []anthropic.BatchRequest) (*anthropic.MessageBatch, error) {
+	err := anthropic.ValidateBatchRequests(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	body := struct {
+		Requests []anthropic.BatchRequest `json:"requests"`
+	}{
+		Requests: requests,
+	}
+
+	betas := []string{MessageBatchesBeta}
+	for _, batchRequest := range requests {
+		betas = append(betas, requiredBetas(batchRequest.Params)...)
+	}
+
+	request, err := c.newJSONRequest(ctx, http.MethodPost, c.batchesURL(""), body, betas...)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &anthropic.MessageBatch{}
+	err = c.doJSONRequest(request, batch)
+	if err != nil {
+		return nil, fmt.Errorf("error sending create batch request: %w", err)
+	}
+
+	return batch, nil
+}
+
+// GetBatch returns the current status of the batch.
+func (c *Client) GetBatch(ctx context.Context, batchID string) (*anthropic.MessageBatch, error) {
+	request, err := c.newJSONRequest(ctx, http.MethodGet, c.batchesURL(batchID), nil, MessageBatchesBeta)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &anthropic.MessageBatch{}
+	err = c.doJSONRequest(request, batch)
+	if err != nil {
+		return nil, fmt.Errorf("error sending get batch request: %w", err)
+	}
+
+	return batch, nil
+}
+
+// ListBatches returns a page of batches, most recent first. Pass the LastID of a page as the
+// AfterID of the next one while HasMore is set.
+func (c *Client) ListBatches(ctx context.Context, opts anthropic.ListBatchesOptions) (*anthropic.MessageBatchList, error) {
+	requestURL := pageURL(c.batchesURL(""), opts.BeforeID, opts.AfterID, opts.Limit)
+	request, err := c.newJSONRequest(ctx, http.MethodGet, requestURL, nil, MessageBatchesBeta)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &anthropic.MessageBatchList{}
+	err = c.doJSONRequest(request, list)
+	if err != nil {
+		return nil, fmt.Errorf("error sending list batches request: %w", err)
+	}
+
+	return list, nil
+}
+
+// CancelBatch starts canceling the batch. Requests already being processed complete, the batch is
+// ended once they are done.
+func (c *Client) CancelBatch(ctx context.Context, batchID string) (*anthropic.MessageBatch, error) {
+	request, err := c.newJSONRequest(ctx, http.MethodPost, c.batchesURL(batchID)+"/cancel", nil, MessageBatchesBeta)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &anthropic.MessageBatch{}
+	err = c.doJSONRequest(request, batch)
+	if err != nil {
+		return nil, fmt.Errorf("error sending cancel batch request: %w", err)
+	}
+
+	return batch, nil
+}
+
+// DeleteBatch deletes an ended batch and its results.
+func (c *Client) DeleteBatch(ctx context.Context, batchID string) (*anthropic.DeletedMessageBatch, error) {
+	request, err := c.newJSONRequest(ctx, http.MethodDelete, c.batchesURL(batchID), nil, MessageBatchesBeta)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := &anthropic.DeletedMessageBatch{}
+	err = c.doJSONRequest(request, deleted)
+	if err != nil {
+		return nil, fmt.Errorf("error sending delete batch request: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// WaitForBatch polls the batch until it has ended and returns it. A nil policy uses the default
+// intervals.
+func (c *Client) WaitForBatch(ctx context.Context, batchID string, policy *BatchPollPolicy) (*anthropic.MessageBatch, error) {
+	interval, maxInterval := DefaultBatchPollInterval, DefaultBatchMaxPollInterval
+	if policy != nil && policy.Interval > 0 {
+		interval = policy.Interval
+	}
+	if policy != nil && policy.MaxInterval > 0 {
+		maxInterval = policy.MaxInterval
+	}
+
+	for {
+		batch, err := c.GetBatch(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+
+		if batch.Ended() {
+			return batch, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// BatchResults streams the results of an ended batch. Results are not in the order of the
+// requests, match them with their CustomID.
+func (c *Client) BatchResults(ctx context.Context, batchID string) (<-chan *anthropic.BatchResult, <-chan error) {
+	resultCh := make(chan *anthropic.BatchResult)
+	errCh := make(chan error, 1)
+
+	go c.handleBatchResults(ctx, batchID, resultCh, errCh)
+
+	return resultCh, errCh
+}
+
+func (c *Client) handleBatchResults(
+	ctx context.Context,
+	batchID string,
+	resultCh chan<- *anthropic.BatchResult,
+	errCh chan<- error,
+) {
+	defer close(resultCh)
+	defer close(errCh)
+
+	batch, err := c.GetBatch(ctx, batchID)
+	if err != nil {
+		errCh <- err
+		return
+	}
+
+	if !batch.Ended() || batch.ResultsURL == "" {
+		errCh <- fmt.Errorf("results of batch %s are not available, its processing status is %s", batchID, batch.ProcessingStatus)
+		return
+	}
+
+	request, err := c.newJSONRequest(ctx, http.MethodGet, batch.ResultsURL, nil, MessageBatchesBeta)
+	if err != nil {
+		errCh <- err
+		return
+	}
+
+	response, err := c.doRequest(request)
+	if err != nil {
+		errCh <- fmt.Errorf("error sending batch results request: %w", err)
+		return
+	}
+	defer response.Body.Close()
+
+	err = processBatchResults(ctx, response.Body, resultCh)
+	if err != nil {
+		errCh <- err
+	}
+}
+
+// processBatchResults decodes the JSONL results file, one result per line.
+func processBatchResults(ctx context.Context, reader io.Reader, resultCh chan<- *anthropic.BatchResult) error {
+	decoder := json.NewDecoder(reader)
+	for {
+		result := &anthropic.BatchResult{}
+		err := decoder.Decode(result)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error decoding batch result: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case resultCh <- result:
+		}
+	}
+}
+
+// batchesURL returns the URL of the batch, or of the batches endpoint for an empty batchID.
+func (c *Client) batchesURL(batchID string) string {
+	if batchID == "" {
+		return fmt.Sprintf("%s/v1/messages/batches", c.baseURL)
+	}
+	return fmt.Sprintf("%s/v1/messages/batches/%s", c.baseURL, url.PathEscape(batchID))
+}
@@ -0,0 +1,255 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+const batchResults = `{"custom_id":"first","result":{"type":"succeeded","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-3-5-sonnet-20241022","content":[{"type":"text","text":"Hello!"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":2}}}}
+{"custom_id":"second","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}}}
+{"custom_id":"third","result":{"type":"expired"}}
+`
+
+func batchRequests() []anthropic.BatchRequest {
+	return []anthropic.BatchRequest{
+		{
+			CustomID: "first",
+			Params: &anthropic.MessageRequest{
+				Model:             anthropic.Claude35Sonnet,
+				MaxTokensToSample: 256,
+				Messages: []anthropic.MessagePartRequest{{
+					Role:    "user",
+					Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+				}},
+			},
+		},
+	}
+}
+
+func TestCreateBatch(t *testing.T) {
+	var betaHeader string
+	var body struct {
+		Requests []anthropic.BatchRequest `json:"requests"`
+	}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/messages/batches" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		betaHeader = r.Header.Get("anthropic-beta")
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch","processing_status":"in_progress","request_counts":{"processing":1},"created_at":"2024-10-01T12:00:00Z","expires_at":"2024-10-02T12:00:00Z"}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	batch, err := client.CreateBatch(context.Background(), batchRequests())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if batch.ID != "msgbatch_1" || batch.ProcessingStatus != anthropic.BatchStatusInProgress || batch.RequestCounts.Processing != 1 {
+		t.Errorf("Unexpected batch %+v", batch)
+	}
+
+	if betaHeader != MessageBatchesBeta {
+		t.Errorf("Expected anthropic-beta %q, got %q", MessageBatchesBeta, betaHeader)
+	}
+
+	if len(body.Requests) != 1 || body.Requests[0].CustomID != "first" || body.Requests[0].Params.Model != anthropic.Claude35Sonnet {
+		t.Errorf("Unexpected request body %+v", body)
+	}
+}
+
+func TestCreateBatchValidation(t *testing.T) {
+	client, err := MakeClient(Config{APIKey: "fake-api-key"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	requests := append(batchRequests(), batchRequests()...)
+	_, err = client.CreateBatch(context.Background(), requests)
+
+	expErr := `duplicate batch request custom_id "first"`
+	if err == nil || err.Error() != expErr {
+		t.Errorf("Expected error %s, got %v", expErr, err)
+	}
+}
+
+func TestListBatches(t *testing.T) {
+	var query string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		fmt.Fprint(w, `{"data":[{"id":"msgbatch_2"},{"id":"msgbatch_1"}],"has_more":true,"first_id":"msgbatch_2","last_id":"msgbatch_1"}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	list, err := client.ListBatches(context.Background(), anthropic.ListBatchesOptions{AfterID: "msgbatch_3", Limit: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if query != "after_id=msgbatch_3&limit=2" {
+		t.Errorf("Unexpected query %q", query)
+	}
+
+	if len(list.Data) != 2 || !list.HasMore || list.LastID != "msgbatch_1" {
+		t.Errorf("Unexpected list %+v", list)
+	}
+}
+
+func TestCancelAndDeleteBatch(t *testing.T) {
+	requests := []string{}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodDelete {
+			fmt.Fprint(w, `{"id":"msgbatch_1","type":"message_batch_deleted"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"canceling"}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	batch, err := client.CancelBatch(context.Background(), "msgbatch_1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if batch.ProcessingStatus != anthropic.BatchStatusCanceling {
+		t.Errorf("Expected a canceling batch, got %s", batch.ProcessingStatus)
+	}
+
+	deleted, err := client.DeleteBatch(context.Background(), "msgbatch_1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deleted.Type != "message_batch_deleted" {
+		t.Errorf("Unexpected deleted batch %+v", deleted)
+	}
+
+	expected := []string{"POST /v1/messages/batches/msgbatch_1/cancel", "DELETE /v1/messages/batches/msgbatch_1"}
+	if fmt.Sprint(requests) != fmt.Sprint(expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+}
+
+func TestWaitForBatchAndResults(t *testing.T) {
+	var polls int32
+	var testServer *httptest.Server
+	testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/messages/batches/msgbatch_1":
+			if atomic.AddInt32(&polls, 1) < 3 {
+				fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"in_progress"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"id":"msgbatch_1","processing_status":"ended","results_url":"%s/v1/messages/batches/msgbatch_1/results"}`, testServer.URL)
+		case "/v1/messages/batches/msgbatch_1/results":
+			if r.Header.Get("X-Api-Key") != "fake-api-key" {
+				t.Error("Expected the results request to be authenticated")
+			}
+			fmt.Fprint(w, batchResults)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	batch, err := client.WaitForBatch(context.Background(), "msgbatch_1", &BatchPollPolicy{
+		Interval:    time.Millisecond,
+		MaxInterval: 2 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !batch.Ended() || atomic.LoadInt32(&polls) != 3 {
+		t.Fatalf("Expected the batch to end after 3 polls, got %d polls", polls)
+	}
+
+	resultCh, errCh := client.BatchResults(context.Background(), "msgbatch_1")
+	results := map[string]*anthropic.BatchResult{}
+	for result := range resultCh {
+		results[result.CustomID] = result
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+
+	if !results["first"].Succeeded() || results["first"].Message.Text() != "Hello!" {
+		t.Errorf("Unexpected succeeded result %+v", results["first"])
+	}
+
+	if results["second"].Type != anthropic.BatchResultErrored || results["second"].Error.Type != "invalid_request_error" {
+		t.Errorf("Unexpected errored result %+v", results["second"])
+	}
+
+	if results["third"].Type != anthropic.BatchResultExpired {
+		t.Errorf("Unexpected expired result %+v", results["third"])
+	}
+}
+
+func TestBatchResultsNotEnded(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"in_progress"}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultCh, errCh := client.BatchResults(context.Background(), "msgbatch_1")
+	for range resultCh {
+		t.Error("Expected no results")
+	}
+
+	expErr := "results of batch msgbatch_1 are not available, its processing status is in_progress"
+	if err := <-errCh; err == nil || err.Error() != expErr {
+		t.Errorf("Expected error %s, got %v", expErr, err)
+	}
+}
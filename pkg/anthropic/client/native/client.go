@@ -1,26 +1,46 @@
 package native
 
 import (
+	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 )
 
 type Client struct {
-	httpClient *http.Client
-	apiKey     string
-	baseURL    string
-	beta       string
-	cache      string
+	httpClient  *http.Client
+	apiKey      string
+	baseURL     string
+	beta        string
+	cache       string
+	retryPolicy *anthropic.RetryPolicy
+	rateLimiter *RateLimiter
+	logger      requestLogger
 }
 
 type Config struct {
 	APIKey  string
 	BaseURL string
 	Beta    string
-	Cache   string
+	// Optional anthropic-beta value enabling prompt caching, e.g. PromptCachingBeta. It is sent
+	// alongside Beta.
+	Cache string
 	// Optional (defaults to http.DefaultClient)
 	HTTPClient *http.Client
+	// Optional (requests are not retried when nil), e.g. anthropic.DefaultRetryPolicy()
+	RetryPolicy *anthropic.RetryPolicy
+	// Optional (requests are not throttled when nil), e.g. NewRateLimiter(RateLimits{...})
+	RateLimiter *RateLimiter
+	// Optional (nothing is logged when nil). Requests and responses are logged at debug level,
+	// retries at warn level.
+	Logger *slog.Logger
+	// LogBodies includes the request and response bodies in the logs. It is off by default, as
+	// bodies contain the full prompts and completions.
+	LogBodies bool
+	// Optional (defaults to DefaultLogBodyLimit) number of bytes of a body logged, -1 logs bodies
+	// in full.
+	LogBodyLimit int
 }
 
 func MakeClient(cfg Config) (*Client, error) {
@@ -36,11 +56,44 @@ func MakeClient(cfg Config) (*Client, error) {
 		cfg.HTTPClient = http.DefaultClient
 	}
 
+	if cfg.LogBodyLimit == 0 {
+		cfg.LogBodyLimit = DefaultLogBodyLimit
+	}
+
 	return &Client{
-		httpClient: cfg.HTTPClient,
-		apiKey:     cfg.APIKey,
-		baseURL:    cfg.BaseURL,
-		beta:       cfg.Beta,
-		cache:      cfg.Cache,
+		httpClient:  cfg.HTTPClient,
+		apiKey:      cfg.APIKey,
+		baseURL:     cfg.BaseURL,
+		beta:        cfg.Beta,
+		cache:       cfg.Cache,
+		retryPolicy: cfg.RetryPolicy,
+		rateLimiter: cfg.RateLimiter,
+		logger: requestLogger{
+			logger:    cfg.Logger,
+			logBodies: cfg.LogBodies,
+			bodyLimit: cfg.LogBodyLimit,
+		},
 	}, nil
 }
+
+// betaHeader returns the value of the anthropic-beta header, combining the configured beta and
+// cache features with the features required by the endpoint or request, each sent once.
+func (c *Client) betaHeader(required ...string) string {
+	features := []string{}
+	seen := map[string]bool{}
+	for _, feature := range append([]string{c.beta, c.cache}, required...) {
+		if feature != "" && !seen[feature] {
+			features = append(features, feature)
+			seen[feature] = true
+		}
+	}
+	return strings.Join(features, ",")
+}
+
+// requiredBetas returns the beta features required by the content of req.
+func requiredBetas(req *anthropic.MessageRequest) []string {
+	if req == nil {
+		return nil
+	}
+	return req.ComputerUseBetas()
+}
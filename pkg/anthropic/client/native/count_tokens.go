@@ -0,0 +1,32 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+// CountTokens returns the number of input tokens req would use, counting its model, system prompt,
+// tools and messages.
+func (c *Client) CountTokens(ctx context.Context, req *anthropic.MessageRequest) (int, error) {
+	err := anthropic.ValidateCountTokensRequest(req)
+	if err != nil {
+		return 0, err
+	}
+
+	requestURL := fmt.Sprintf("%s/v1/messages/count_tokens", c.baseURL)
+	request, err := c.newJSONRequest(ctx, http.MethodPost, requestURL, anthropic.NewCountTokensRequest(req), append(requiredBetas(req), TokenCountingBeta)...)
+	if err != nil {
+		return 0, err
+	}
+
+	countResponse := &anthropic.CountTokensResponse{}
+	err = c.doJSONRequest(request, countResponse)
+	if err != nil {
+		return 0, fmt.Errorf("error sending count tokens request: %w", err)
+	}
+
+	return countResponse.InputTokens, nil
+}
@@ -0,0 +1,96 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+func TestCountTokens(t *testing.T) {
+	var path, betaHeader string
+	var body map[string]interface{}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		betaHeader = r.Header.Get("anthropic-beta")
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"input_tokens":42}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model:             anthropic.Claude35Sonnet,
+		SystemPrompt:      "You are a helpful assistant.",
+		MaxTokensToSample: 1024,
+		Tools: []anthropic.Tool{{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			InputSchema: anthropic.InputSchema{Type: "object"},
+		}},
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+	}
+
+	tokens, err := client.CountTokens(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if tokens != 42 {
+		t.Errorf("Expected 42 input tokens, got %d", tokens)
+	}
+
+	if path != "/v1/messages/count_tokens" {
+		t.Errorf("Expected request to /v1/messages/count_tokens, got %s", path)
+	}
+
+	if betaHeader != TokenCountingBeta {
+		t.Errorf("Expected anthropic-beta %q, got %q", TokenCountingBeta, betaHeader)
+	}
+
+	for _, field := range []string{"model", "system", "tools", "messages"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("Expected %s in the request body", field)
+		}
+	}
+
+	if _, ok := body["max_tokens"]; ok {
+		t.Errorf("Expected no max_tokens in the request body")
+	}
+}
+
+func TestCountTokensErrorHandling(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"messages: field required"}}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = client.CountTokens(context.Background(), &anthropic.MessageRequest{Model: anthropic.Claude35Sonnet})
+	if !errors.Is(err, anthropic.ErrAnthropicInvalidRequest) {
+		t.Errorf("Expected invalid request error, got %v", err)
+	}
+}
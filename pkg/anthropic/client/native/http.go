@@ -2,7 +2,15 @@
 package native
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 )
@@ -10,21 +18,150 @@ import (
 const (
 	// AnthropicAPIVersion is the version of the Anthropics API that this client is compatible with.
 	AnthropicAPIVersion = "2023-06-01"
+
+	// PromptCachingBeta is the anthropic-beta feature enabling cache_control breakpoints.
+	PromptCachingBeta = "prompt-caching-2024-07-31"
+
+	// TokenCountingBeta is the anthropic-beta feature enabling the count tokens endpoint.
+	TokenCountingBeta = "token-counting-2024-11-01"
+
+	// MessageBatchesBeta is the anthropic-beta feature enabling the message batches endpoints.
+	MessageBatchesBeta = "message-batches-2024-09-24"
+
+	// maxErrorBodySize limits how much of an error response is read.
+	maxErrorBodySize = 1 << 20
 )
 
 // doRequest sends an HTTP request and returns the response, handling any non-OK HTTP status codes.
+// Failed attempts are retried according to the client's retry policy.
 func (c *Client) doRequest(request *http.Request) (*http.Response, error) {
-	request.Header.Add("anthropic-version", AnthropicAPIVersion)
+	for attempt := 1; ; attempt++ {
+		response, statusCode, header, err := c.sendRequest(request, attempt)
+		if err == nil {
+			return response, nil
+		}
+
+		if !c.retryPolicy.ShouldRetry(attempt, statusCode, err) {
+			return nil, err
+		}
+
+		c.logger.logRetry(request.Context(), attempt, err)
+		if c.retryPolicy.Wait(request.Context(), attempt, header) != nil {
+			return nil, err
+		}
+
+		request, err = rewindRequest(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sendRequest sends a single attempt of an HTTP request. A non-OK response is returned as an error
+// along with its status code and header, for the caller to decide whether to retry.
+func (c *Client) sendRequest(request *http.Request, attempt int) (*http.Response, int, http.Header, error) {
+	request.Header.Set("anthropic-version", AnthropicAPIVersion)
+
+	c.logger.logRequest(request.Context(), request, attempt)
+	start := time.Now()
 
 	response, err := c.httpClient.Do(request)
 	if err != nil {
-		return nil, err
+		return nil, 0, nil, err
 	}
+	c.logger.logResponse(request.Context(), response, time.Since(start))
 
 	if response.StatusCode != http.StatusOK {
-		err = anthropic.MapHTTPStatusCodeToError(response.StatusCode)
-		return nil, err
+		return nil, response.StatusCode, response.Header, readAPIError(response)
+	}
+
+	return response, 0, nil, nil
+}
+
+// readAPIError reads the error returned in a non-OK response.
+func readAPIError(response *http.Response) error {
+	defer response.Body.Close()
+
+	// the status code is enough to report the error if the body can't be read
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+
+	return anthropic.NewAPIError(response.StatusCode, response.Header, body)
+}
+
+// rewindRequest returns a copy of request with its body reset, so it can be sent again.
+func rewindRequest(request *http.Request) (*http.Request, error) {
+	retry := request.Clone(request.Context())
+	if request.GetBody == nil {
+		return retry, nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("error rewinding request body: %w", err)
+	}
+	retry.Body = body
+
+	return retry, nil
+}
+
+// newJSONRequest creates an authenticated request to url, encoding body as JSON when it isn't nil.
+// The required beta features are sent alongside the configured ones.
+func (c *Client) newJSONRequest(ctx context.Context, method, url string, body interface{}, required ...string) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("error creating new request: %w", err)
 	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("X-Api-Key", c.apiKey)
+	if beta := c.betaHeader(required...); len(beta) > 0 {
+		request.Header.Set("anthropic-beta", beta)
+	}
+
+	return request, nil
+}
 
-	return response, nil
+// doJSONRequest sends request and decodes its JSON response into out.
+func (c *Client) doJSONRequest(request *http.Request, out interface{}) error {
+	response, err := c.doRequest(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	err = json.NewDecoder(response.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// pageURL adds the pagination parameters of list endpoints to requestURL.
+func pageURL(requestURL, beforeID, afterID string, limit int) string {
+	query := url.Values{}
+	if beforeID != "" {
+		query.Set("before_id", beforeID)
+	}
+	if afterID != "" {
+		query.Set("after_id", afterID)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	if len(query) == 0 {
+		return requestURL
+	}
+	return requestURL + "?" + query.Encode()
 }
@@ -0,0 +1,112 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/utils"
+)
+
+// DefaultLogBodyLimit is the number of bytes of a request or response body logged when LogBodies
+// is enabled and no limit is configured.
+const DefaultLogBodyLimit = 4096
+
+// redactedHeaders are logged with their value replaced, as they hold credentials.
+var redactedHeaders = map[string]bool{
+	"X-Api-Key":     true,
+	"Authorization": true,
+}
+
+// requestLogger logs the HTTP exchanges of the client; a nil logger logs nothing.
+type requestLogger struct {
+	logger    *slog.Logger
+	logBodies bool
+	bodyLimit int
+}
+
+func (l requestLogger) logRequest(ctx context.Context, request *http.Request, attempt int) {
+	if l.logger == nil || !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", request.Method),
+		slog.String("url", request.URL.String()),
+		slog.Int("attempt", attempt),
+		slog.Any("headers", redactHeaders(request.Header)),
+	}
+
+	if l.logBodies && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			attrs = append(attrs, slog.String("body", utils.Truncate(string(data), l.bodyLimit)))
+		}
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "sending request", attrs...)
+}
+
+// logResponse logs a response received after duration. Non-streaming bodies are read and replaced
+// so they can still be decoded.
+func (l requestLogger) logResponse(ctx context.Context, response *http.Response, duration time.Duration) {
+	if l.logger == nil || !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Int("status", response.StatusCode),
+		slog.Duration("duration", duration),
+		slog.String("request_id", response.Header.Get("request-id")),
+	}
+
+	streaming := strings.HasPrefix(response.Header.Get("Content-Type"), "text/event-stream")
+	if l.logBodies && !streaming {
+		data, err := io.ReadAll(response.Body)
+		response.Body.Close()
+		response.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+		attrs = append(attrs, slog.String("body", utils.Truncate(string(data), l.bodyLimit)))
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "received response", attrs...)
+}
+
+func (l requestLogger) logRetry(ctx context.Context, attempt int, err error) {
+	if l.logger == nil {
+		return
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelWarn, "retrying request",
+		slog.Int("attempt", attempt),
+		slog.String("error", err.Error()),
+	)
+}
+
+// redactHeaders returns a copy of header safe to log.
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name := range redacted {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{"[REDACTED]"}
+		}
+	}
+	return redacted
+}
+
+// errReader replays the error hit while reading a body that was logged, if any.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,96 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+func TestMessageLogging(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"a secret answer"}]}`)
+	}))
+	defer testServer.Close()
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("a secret question")},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		logBodies    bool
+		bodyLimit    int
+		contains     []string
+		notContains  []string
+		expectedText string
+	}{
+		{
+			name:        "bodies off by default",
+			contains:    []string{"sending request", "received response", "[REDACTED]"},
+			notContains: []string{"fake-api-key", "a secret question", "a secret answer"},
+		},
+		{
+			name:        "bodies",
+			logBodies:   true,
+			bodyLimit:   -1,
+			contains:    []string{"a secret question", "a secret answer"},
+			notContains: []string{"fake-api-key", "truncated"},
+		},
+		{
+			name:        "truncated bodies",
+			logBodies:   true,
+			bodyLimit:   10,
+			contains:    []string{"bytes truncated"},
+			notContains: []string{"fake-api-key", "a secret question", "a secret answer"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+			client, err := MakeClient(Config{
+				APIKey:       "fake-api-key",
+				BaseURL:      testServer.URL,
+				Logger:       slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+				LogBodies:    test.logBodies,
+				LogBodyLimit: test.bodyLimit,
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			response, err := client.Message(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// logging the body must not consume it
+			if response.Text() != "a secret answer" {
+				t.Errorf("Expected message %q, got %q", "a secret answer", response.Text())
+			}
+
+			for _, expected := range test.contains {
+				if !strings.Contains(logs.String(), expected) {
+					t.Errorf("Expected logs to contain %q, got %s", expected, logs.String())
+				}
+			}
+
+			for _, unexpected := range test.notContains {
+				if strings.Contains(logs.String(), unexpected) {
+					t.Errorf("Expected logs not to contain %q, got %s", unexpected, logs.String())
+				}
+			}
+		})
+	}
+}
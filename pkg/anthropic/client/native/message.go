@@ -16,7 +16,19 @@ func (c *Client) Message(ctx context.Context, req *anthropic.MessageRequest) (*a
 		return nil, err
 	}
 
-	return c.sendMessageRequest(ctx, req)
+	reservation, err := c.rateLimiter.reserve(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendMessageRequest(ctx, req)
+	if err != nil {
+		c.rateLimiter.settle(reservation, nil)
+		return nil, err
+	}
+	c.rateLimiter.settle(reservation, &response.Usage)
+
+	return response, nil
 }
 
 func (c *Client) sendMessageRequest(
@@ -28,8 +40,6 @@ func (c *Client) sendMessageRequest(
 		return nil, fmt.Errorf("error marshalling message request: %w", err)
 	}
 
-	fmt.Printf("Sending payload %v", bytes.NewBuffer((data)))
-
 	requestURL := fmt.Sprintf("%s/v1/messages", c.baseURL)
 	request, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(data))
 	if err != nil {
@@ -37,8 +47,8 @@ func (c *Client) sendMessageRequest(
 	}
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("X-Api-Key", c.apiKey)
-	if len(c.beta) > 0 {
-		request.Header.Set("anthropic-beta", c.beta)
+	if beta := c.betaHeader(requiredBetas(req)...); len(beta) > 0 {
+		request.Header.Set("anthropic-beta", beta)
 	}
 
 	// Use the doRequest method to send the HTTP request
@@ -54,6 +64,8 @@ func (c *Client) sendMessageRequest(
 	if err != nil {
 		return nil, fmt.Errorf("error decoding message response: %w", err)
 	}
+	messageResponse.RateLimit = anthropic.ParseRateLimit(response.Header)
+	messageResponse.RequestID = response.Header.Get("request-id")
 
 	return messageResponse, nil
 }
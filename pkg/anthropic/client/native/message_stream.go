@@ -45,34 +45,91 @@ func (c *Client) handleMessageStreaming(
 		return
 	}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/messages", c.baseURL), bytes.NewBuffer(data))
+	reservation, err := c.rateLimiter.reserve(ctx, req)
 	if err != nil {
-		errCh <- fmt.Errorf("error creating new request: %w", err)
+		errCh <- err
 		return
 	}
 
+	stats, err := c.streamMessage(ctx, data, requiredBetas(req), msCh)
+	c.rateLimiter.settle(reservation, stats.usage)
+	if err != nil {
+		errCh <- err
+	}
+}
+
+// streamMessage sends the streaming request and forwards its events. Failed requests and streams
+// that fail before delivering any event are retried as a whole, sharing the attempts of the retry
+// policy. Once events have been delivered the error is returned to the caller.
+func (c *Client) streamMessage(
+	ctx context.Context,
+	data []byte,
+	betas []string,
+	msCh chan<- *anthropic.MessageStreamResponse,
+) (streamStats, error) {
+	for attempt := 1; ; attempt++ {
+		stats := streamStats{}
+		response, statusCode, header, err := c.sendMessageStreamRequest(ctx, data, betas, attempt)
+		if err == nil {
+			stats, err = c.processMessageSseStream(ctx, response.Body, response.Header, msCh)
+			response.Body.Close()
+			if err == nil {
+				return stats, nil
+			}
+		}
+
+		if stats.delivered > 0 || !c.retryPolicy.ShouldRetry(attempt, statusCode, err) {
+			return stats, err
+		}
+
+		c.logger.logRetry(ctx, attempt, err)
+		if c.retryPolicy.Wait(ctx, attempt, header) != nil {
+			return stats, err
+		}
+	}
+}
+
+// sendMessageStreamRequest sends a single attempt of the streaming request, see sendRequest.
+func (c *Client) sendMessageStreamRequest(ctx context.Context, data []byte, betas []string, attempt int) (*http.Response, int, http.Header, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/messages", c.baseURL), bytes.NewBuffer(data))
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error creating new request: %w", err)
+	}
+
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("X-Api-Key", c.apiKey)
 	request.Header.Set("Accept", "text/event-stream")
-	if len(c.beta) > 0 {
-		request.Header.Set("anthropic-beta", c.beta)
+	if beta := c.betaHeader(betas...); len(beta) > 0 {
+		request.Header.Set("anthropic-beta", beta)
 	}
 
-	response, err := c.doRequest(request)
+	response, statusCode, header, err := c.sendRequest(request, attempt)
 	if err != nil {
-		errCh <- fmt.Errorf("error sending message request: %w", err)
-		return
+		return nil, statusCode, header, fmt.Errorf("error sending message request: %w", err)
 	}
-	defer response.Body.Close()
 
-	err = c.processMessageSseStream(response.Body, msCh)
-	if err != nil {
-		errCh <- err
-	}
+	return response, 0, nil, nil
+}
+
+// streamStats summarizes the events read from a message stream.
+type streamStats struct {
+	delivered int
+	// usage is nil until the message_start event is received
+	usage *anthropic.MessageUsage
 }
 
-func (c *Client) processMessageSseStream(reader io.Reader, events chan<- *anthropic.MessageStreamResponse) error {
+// processMessageSseStream forwards the events read from the stream, setting the rate limit and
+// request id from the response header on the message_start event. It stops when ctx is done, so a
+// consumer that stopped reading doesn't block it.
+func (c *Client) processMessageSseStream(
+	ctx context.Context,
+	reader io.Reader,
+	header http.Header,
+	events chan<- *anthropic.MessageStreamResponse,
+) (streamStats, error) {
+	stats := streamStats{}
 	scanner := bufio.NewScanner(reader)
+	parser := anthropic.NewMessageEventParser()
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -83,26 +140,43 @@ func (c *Client) processMessageSseStream(reader io.Reader, events chan<- *anthro
 			event := &anthropic.MessageEvent{}
 			err := json.Unmarshal([]byte(data), event)
 			if err != nil {
-				return fmt.Errorf("error decoding event data: %w", err)
+				return stats, fmt.Errorf("error decoding event data: %w", err)
 			}
 
-			msg, err := anthropic.ParseMessageEvent(anthropic.MessageEventType(event.Type), data)
+			msg, err := parser.Parse(anthropic.MessageEventType(event.Type), data)
 
 			if err != nil {
 				if _, ok := err.(anthropic.UnsupportedEventType); ok {
 					// ignore unsupported event types
 				} else {
-					return fmt.Errorf("error processing message stream: %v", err)
+					return stats, fmt.Errorf("error processing message stream: %w", err)
 				}
 			}
 
-			events <- msg
+			switch msg.Type {
+			case string(anthropic.MessageEventTypeMessageStart):
+				msg.RateLimit = anthropic.ParseRateLimit(header)
+				msg.RequestID = header.Get("request-id")
+				usage := anthropic.MessageUsage(msg.Usage)
+				stats.usage = &usage
+			case string(anthropic.MessageEventTypeMessageDelta):
+				if stats.usage != nil {
+					stats.usage.OutputTokens = msg.Usage.OutputTokens
+				}
+			}
+
+			select {
+			case events <- msg:
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			}
+			stats.delivered++
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading from stream: %w", err)
+		return stats, fmt.Errorf("error reading from stream: %w", err)
 	}
 
-	return nil
+	return stats, nil
 }
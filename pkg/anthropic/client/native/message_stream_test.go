@@ -2,11 +2,13 @@ package native
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 )
@@ -233,8 +235,208 @@ func TestMessageStreamErrorInStream(t *testing.T) {
 	}
 
 	// Check the error
-	expectedError := "error processing message stream: error type: overload_error, message: Overloaded"
+	expectedError := "error processing message stream: unknown error occurred: Overloaded (type overload_error)"
 	if expectedError != err.Error() {
 		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
 	}
 }
+
+func TestMessageStreamToolUse(t *testing.T) {
+	// Create a test server to mock the Anthropics API
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		output := []byte{}
+		output = append(output, []byte("event: message_start\n")...)
+		output = append(output, []byte("data: {\"type\": \"message_start\", \"message\": {\"id\": \"msg_01\", \"type\": \"message\", \"role\": \"assistant\", \"content\": [], \"model\": \"claude-3-opus-20240229\", \"stop_reason\": null, \"stop_sequence\": null, \"usage\": {\"input_tokens\": 25, \"output_tokens\": 1}}}\n\n")...)
+		output = append(output, []byte("event: content_block_start\n")...)
+		output = append(output, []byte("data: {\"type\": \"content_block_start\", \"index\": 0, \"content_block\": {\"type\": \"tool_use\", \"id\": \"toolu_01\", \"name\": \"get_weather\", \"input\": {}}}\n\n")...)
+		output = append(output, []byte("event: content_block_delta\n")...)
+		output = append(output, []byte("data: {\"type\": \"content_block_delta\", \"index\": 0, \"delta\": {\"type\": \"input_json_delta\", \"partial_json\": \"{\\\"city\\\": \"}}\n\n")...)
+		output = append(output, []byte("event: content_block_delta\n")...)
+		output = append(output, []byte("data: {\"type\": \"content_block_delta\", \"index\": 0, \"delta\": {\"type\": \"input_json_delta\", \"partial_json\": \"\\\"Charleston\\\"}\"}}\n\n")...)
+		output = append(output, []byte("event: content_block_stop\n")...)
+		output = append(output, []byte("data: {\"type\": \"content_block_stop\", \"index\": 0}\n\n")...)
+		output = append(output, []byte("event: message_delta\n")...)
+		output = append(output, []byte("data: {\"type\": \"message_delta\", \"delta\": {\"stop_reason\": \"tool_use\", \"stop_sequence\":null}, \"usage\":{\"output_tokens\": 15}}\n\n")...)
+		output = append(output, []byte("event: message_stop\n")...)
+		output = append(output, []byte("data: {\"type\": \"message_stop\"}\n\n")...)
+		w.Write(output)
+	}))
+	defer testServer.Close()
+
+	// Create a new client with the test server's URL
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Prepare a message request
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude3Opus,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("What's the weather in Charleston?")},
+		}},
+		Stream: true,
+	}
+
+	rCh, errCh := client.MessageStream(context.Background(), request)
+
+	var toolUse *anthropic.MessageStreamContentBlock
+	for chunk := range rCh {
+		if chunk.Type == "content_block_stop" {
+			toolUse = chunk.ContentBlock
+		}
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if toolUse == nil {
+		t.Fatal("Expected an assembled tool_use block, got none")
+	}
+
+	if toolUse.ID != "toolu_01" || toolUse.Name != "get_weather" {
+		t.Errorf("Expected tool_use toolu_01/get_weather, got %s/%s", toolUse.ID, toolUse.Name)
+	}
+
+	if toolUse.Input["city"] != "Charleston" {
+		t.Errorf("Expected input city Charleston, got %v", toolUse.Input["city"])
+	}
+}
+
+func TestMessageStreamRetry(t *testing.T) {
+	attempts := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("anthropic-ratelimit-tokens-remaining", "1200")
+
+		if attempts == 1 {
+			// fails before any event is delivered
+			fmt.Fprint(w, "event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"overloaded_error\", \"message\": \"Overloaded\"}}\n\n")
+			return
+		}
+
+		fmt.Fprint(w, "event: message_start\ndata: {\"type\": \"message_start\", \"message\": {\"id\": \"msg_1\", \"type\": \"message\", \"role\": \"assistant\", \"content\": [], \"usage\": {\"input_tokens\": 5, \"output_tokens\": 1}}}\n\n")
+		fmt.Fprint(w, "event: content_block_start\ndata: {\"type\": \"content_block_start\", \"index\": 0, \"content_block\": {\"type\": \"text\", \"text\": \"\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\": \"content_block_delta\", \"index\": 0, \"delta\": {\"type\": \"text_delta\", \"text\": \"Hi\"}}\n\n")
+		fmt.Fprint(w, "event: content_block_stop\ndata: {\"type\": \"content_block_stop\", \"index\": 0}\n\n")
+		fmt.Fprint(w, "event: message_stop\ndata: {\"type\": \"message_stop\"}\n\n")
+
+		if attempts == 3 {
+			// fails after events were delivered
+			fmt.Fprint(w, "event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"overloaded_error\", \"message\": \"Overloaded\"}}\n\n")
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+		RetryPolicy: &anthropic.RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			Retryable: func(statusCode int, err error) bool {
+				return true
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+		Stream: true,
+	}
+
+	msCh, errCh := client.MessageStream(context.Background(), request)
+	response, err := anthropic.AccumulateMessageStream(context.Background(), msCh, errCh)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+
+	if response.Text() != "Hi" {
+		t.Errorf("Expected message %q, got %q", "Hi", response.Text())
+	}
+
+	if response.RateLimit == nil || response.RateLimit.TokensRemaining != 1200 {
+		t.Errorf("Unexpected rate limit %+v", response.RateLimit)
+	}
+
+	// once events have been delivered the stream is not retried
+	msCh, errCh = client.MessageStream(context.Background(), request)
+	_, err = anthropic.AccumulateMessageStream(context.Background(), msCh, errCh)
+	if err == nil {
+		t.Fatal("Expected an error after events were delivered")
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestMessageStreamRetryAttempts(t *testing.T) {
+	attempts := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts == 1 {
+			// the stream is opened, then fails before any event is delivered
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"overloaded_error\", \"message\": \"Overloaded\"}}\n\n")
+			return
+		}
+
+		w.WriteHeader(529)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+		RetryPolicy: &anthropic.RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+		Stream: true,
+	}
+
+	msCh, errCh := client.MessageStream(context.Background(), request)
+	_, err = anthropic.AccumulateMessageStream(context.Background(), msCh, errCh)
+
+	var apiErr *anthropic.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 529 {
+		t.Fatalf("Expected an overloaded error, got %v", err)
+	}
+
+	// stream failures and failed requests share the attempts of the retry policy
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
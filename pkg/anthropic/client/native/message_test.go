@@ -3,10 +3,12 @@ package native
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 )
@@ -19,10 +21,9 @@ func TestMessage(t *testing.T) {
 			Type:  "testType",
 			Model: "testModel",
 			Role:  "user",
-			Content: []anthropic.MessagePartResponse{{
-				Type: "text",
-				Text: "Test message",
-			}},
+			Content: []anthropic.ContentBlock{
+				anthropic.NewTextContentBlock("Test message"),
+			},
 			Usage: anthropic.MessageUsage{
 				InputTokens:  10,
 				OutputTokens: 5,
@@ -60,8 +61,8 @@ func TestMessage(t *testing.T) {
 
 	// Check the response
 	expectedContent := "Test message"
-	if len(response.Content) == 0 || response.Content[0].Text != expectedContent {
-		t.Errorf("Expected message %q, got %q", expectedContent, response.Content[0].Text)
+	if len(response.Content) == 0 || response.Text() != expectedContent {
+		t.Errorf("Expected message %q, got %q", expectedContent, response.Text())
 	}
 }
 
@@ -131,3 +132,287 @@ func TestMessageIncompatibleModel(t *testing.T) {
 		)
 	}
 }
+
+func TestMessageCacheBetaHeader(t *testing.T) {
+	var betaHeader string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		betaHeader = r.Header.Get("anthropic-beta")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[],"usage":{"input_tokens":3,"output_tokens":1,"cache_creation_input_tokens":2048,"cache_read_input_tokens":0}}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+		Beta:    "tools-2024-04-04",
+		Cache:   PromptCachingBeta,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role: "user",
+			Content: []anthropic.ContentBlock{
+				anthropic.SetCacheControl(anthropic.NewTextContentBlock("Hello"), anthropic.NewEphemeralCacheControl()),
+			},
+		}},
+	}
+
+	response, err := client.Message(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedBeta := "tools-2024-04-04," + PromptCachingBeta
+	if betaHeader != expectedBeta {
+		t.Errorf("Expected anthropic-beta %q, got %q", expectedBeta, betaHeader)
+	}
+
+	if response.Usage.CacheCreationInputTokens != 2048 {
+		t.Errorf("Expected 2048 cache creation tokens, got %d", response.Usage.CacheCreationInputTokens)
+	}
+}
+
+func TestMessageComputerUseBetaHeader(t *testing.T) {
+	var betaHeader string
+	var body map[string]interface{}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		betaHeader = r.Header.Get("anthropic-beta")
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"tool_use","id":"toolu_1","name":"computer","input":{"action":"screenshot"}}],"usage":{"input_tokens":3,"output_tokens":1}}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+		Beta:    anthropic.ComputerUseBeta,
+		Cache:   PromptCachingBeta,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Tools: []anthropic.Tool{anthropic.NewComputerTool(1024, 768, 1), anthropic.NewBashTool()},
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Take a screenshot")},
+		}},
+	}
+
+	response, err := client.Message(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// the configured beta is sent once
+	expectedBeta := anthropic.ComputerUseBeta + "," + PromptCachingBeta
+	if betaHeader != expectedBeta {
+		t.Errorf("Expected anthropic-beta %q, got %q", expectedBeta, betaHeader)
+	}
+
+	tools, _ := body["tools"].([]interface{})
+	if len(tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %v", body["tools"])
+	}
+	computer := tools[0].(map[string]interface{})
+	if computer["type"] != anthropic.ToolTypeComputer || computer["name"] != anthropic.ToolNameComputer || computer["input_schema"] != nil {
+		t.Errorf("Unexpected computer tool %v", computer)
+	}
+
+	input, err := anthropic.DecodeComputerInput(response.ToolUses()[0].Input)
+	if err != nil || input.Action != anthropic.ComputerActionScreenshot {
+		t.Errorf("Expected a screenshot action, got %+v, %v", input, err)
+	}
+}
+
+func TestMessageRetry(t *testing.T) {
+	attempts := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.Header().Set("retry-after-ms", "1")
+			http.Error(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, 529)
+			return
+		}
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Hi"}]}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:      "fake-api-key",
+		BaseURL:     testServer.URL,
+		RetryPolicy: &anthropic.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+	}
+
+	response, err := client.Message(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+
+	if response.Text() != "Hi" {
+		t.Errorf("Expected message %q, got %q", "Hi", response.Text())
+	}
+}
+
+func TestMessageRetryNotRetryable(t *testing.T) {
+	attempts := 0
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:      "fake-api-key",
+		BaseURL:     testServer.URL,
+		RetryPolicy: &anthropic.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+	}
+
+	_, err = client.Message(context.Background(), request)
+	if !errors.Is(err, anthropic.ErrAnthropicInvalidRequest) {
+		t.Errorf("Expected invalid request error, got %v", err)
+	}
+
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestMessageAPIError(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "req_011")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: Field required"}}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+	}
+
+	_, err = client.Message(context.Background(), request)
+	if !errors.Is(err, anthropic.ErrAnthropicInvalidRequest) {
+		t.Fatalf("Expected invalid request error, got %v", err)
+	}
+
+	var apiErr *anthropic.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an APIError, got %T", err)
+	}
+
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Type != "invalid_request_error" ||
+		apiErr.Message != "max_tokens: Field required" || apiErr.RequestID != "req_011" {
+		t.Errorf("Unexpected APIError %+v", apiErr)
+	}
+}
+
+func TestMessageRateLimitHeaders(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("anthropic-ratelimit-requests-limit", "50")
+		w.Header().Set("anthropic-ratelimit-requests-remaining", "12")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Hi"}]}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:      "fake-api-key",
+		BaseURL:     testServer.URL,
+		RateLimiter: NewRateLimiter(RateLimits{RequestsPerMinute: 50}),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude35Sonnet,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+	}
+
+	response, err := client.Message(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if response.RateLimit == nil || response.RateLimit.RequestsLimit != 50 || response.RateLimit.RequestsRemaining != 12 {
+		t.Errorf("Unexpected rate limit %+v", response.RateLimit)
+	}
+}
+
+func TestMessageComputerUseBetaHeaderVersion(t *testing.T) {
+	var betaHeader string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		betaHeader = r.Header.Get("anthropic-beta")
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[],"usage":{"input_tokens":3,"output_tokens":1}}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{APIKey: "fake-api-key", BaseURL: testServer.URL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	request := &anthropic.MessageRequest{
+		Model: anthropic.Claude37Sonnet,
+		Tools: []anthropic.Tool{anthropic.NewComputerTool_20250124(1024, 768, 0), anthropic.NewTextEditorTool_20250124()},
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Open the editor")},
+		}},
+	}
+
+	_, err = client.Message(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if betaHeader != anthropic.ComputerUseBeta_20250124 {
+		t.Errorf("Expected anthropic-beta %q, got %q", anthropic.ComputerUseBeta_20250124, betaHeader)
+	}
+}
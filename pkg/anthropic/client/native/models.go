@@ -0,0 +1,72 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+// ListModels returns a page of the available models, most recently released first. Pass the
+// LastID of a page as the AfterID of the next one while HasMore is set.
+func (c *Client) ListModels(ctx context.Context, opts anthropic.ListModelsOptions) (*anthropic.ModelList, error) {
+	requestURL := pageURL(fmt.Sprintf("%s/v1/models", c.baseURL), opts.BeforeID, opts.AfterID, opts.Limit)
+	request, err := c.newJSONRequest(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &anthropic.ModelList{}
+	err = c.doJSONRequest(request, list)
+	if err != nil {
+		return nil, fmt.Errorf("error sending list models request: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetModel returns the model identified by modelID, which can also be an alias such as
+// claude-3-5-sonnet-latest.
+func (c *Client) GetModel(ctx context.Context, modelID string) (*anthropic.ModelInfo, error) {
+	requestURL := fmt.Sprintf("%s/v1/models/%s", c.baseURL, url.PathEscape(modelID))
+	request, err := c.newJSONRequest(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &anthropic.ModelInfo{}
+	err = c.doJSONRequest(request, info)
+	if err != nil {
+		return nil, fmt.Errorf("error sending get model request: %w", err)
+	}
+
+	return info, nil
+}
+
+// RegisterAvailableModels lists all the available models and registers the ones the library
+// didn't ship with, so they pass request validation. It returns the listed models.
+func (c *Client) RegisterAvailableModels(ctx context.Context) ([]anthropic.ModelInfo, error) {
+	models := []anthropic.ModelInfo{}
+	opts := anthropic.ListModelsOptions{}
+
+	for {
+		list, err := c.ListModels(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		models = append(models, list.Data...)
+		if !list.HasMore || list.LastID == "" {
+			break
+		}
+		opts.AfterID = list.LastID
+	}
+
+	for _, info := range models {
+		anthropic.RegisterModelInfo(info)
+	}
+
+	return models, nil
+}
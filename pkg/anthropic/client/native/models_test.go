@@ -0,0 +1,101 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+func TestGetModel(t *testing.T) {
+	var path string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		fmt.Fprint(w, `{"type":"model","id":"claude-3-5-sonnet-20241022","display_name":"Claude 3.5 Sonnet (New)","created_at":"2024-10-22T00:00:00Z"}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	info, err := client.GetModel(context.Background(), string(anthropic.Claude35Sonnet))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if path != "/v1/models/claude-3-5-sonnet-latest" {
+		t.Errorf("Unexpected path %s", path)
+	}
+
+	if info.Model() != anthropic.Claude35Sonnet_20241022 || info.DisplayName != "Claude 3.5 Sonnet (New)" || info.CreatedAt.Year() != 2024 {
+		t.Errorf("Unexpected model %+v", info)
+	}
+}
+
+func TestRegisterAvailableModels(t *testing.T) {
+	queries := []string{}
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("after_id") == "" {
+			fmt.Fprint(w, `{"data":[{"type":"model","id":"claude-next-20990101","display_name":"Claude Next"}],"has_more":true,"first_id":"claude-next-20990101","last_id":"claude-next-20990101"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"type":"model","id":"claude-3-opus-20240229","display_name":"Claude 3 Opus"}],"has_more":false,"first_id":"claude-3-opus-20240229","last_id":"claude-3-opus-20240229"}`)
+	}))
+	defer testServer.Close()
+
+	client, err := MakeClient(Config{
+		APIKey:  "fake-api-key",
+		BaseURL: testServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	model := anthropic.Model("claude-next-20990101")
+	if model.IsMessageCompatible() {
+		t.Fatal("Expected the model to be unknown before being registered")
+	}
+
+	models, err := client.RegisterAvailableModels(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(models) != 2 || fmt.Sprint(queries) != "[ after_id=claude-next-20990101]" {
+		t.Errorf("Expected 2 pages to be listed, got %d models from %q", len(models), queries)
+	}
+
+	// a model of an unknown family is assumed to support messages with images and tools
+	if !model.IsMessageCompatible() || !model.IsImageCompatible() || model.IsCompleteCompatible() {
+		t.Errorf("Unexpected capabilities of the registered model")
+	}
+
+	err = anthropic.ValidateMessageRequest(&anthropic.MessageRequest{
+		Model:             model,
+		MaxTokensToSample: 1024,
+		Tools: []anthropic.Tool{{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			InputSchema: anthropic.InputSchema{Type: "object"},
+		}},
+		Messages: []anthropic.MessagePartRequest{{
+			Role: "user",
+			Content: []anthropic.ContentBlock{
+				anthropic.NewImageContentBlock(anthropic.MediaTypePNG, "iVBORw0KGgo="),
+				anthropic.NewTextContentBlock("What's the weather like here?"),
+			},
+		}},
+	})
+	if err != nil {
+		t.Errorf("Expected the registered model to pass validation, got %v", err)
+	}
+}
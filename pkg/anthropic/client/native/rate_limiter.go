@@ -0,0 +1,233 @@
+package native
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+const (
+	// estimatedCharsPerToken is the rough number of characters of English text per token used to
+	// estimate the input tokens of a request before sending it.
+	estimatedCharsPerToken = 4
+	// estimatedImageTokens is the estimated cost of an image, the cost of a ~1.15 megapixel image.
+	estimatedImageTokens = 1600
+)
+
+// RateLimits configures a RateLimiter. Zero limits are not enforced.
+type RateLimits struct {
+	RequestsPerMinute     int
+	InputTokensPerMinute  int
+	OutputTokensPerMinute int
+}
+
+// RateLimiter throttles requests client side so they stay within per minute limits instead of
+// failing with rate limit errors. Input tokens are estimated from the request and output tokens are
+// reserved from max_tokens, both are corrected with the actual usage once the response is received.
+//
+// A RateLimiter is safe for concurrent use, and can be shared by clients using the same API key.
+type RateLimiter struct {
+	mu           sync.Mutex
+	requests     *tokenBucket
+	inputTokens  *tokenBucket
+	outputTokens *tokenBucket
+}
+
+// NewRateLimiter creates a RateLimiter enforcing limits.
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	now := time.Now()
+	return &RateLimiter{
+		requests:     newTokenBucket(limits.RequestsPerMinute, now),
+		inputTokens:  newTokenBucket(limits.InputTokensPerMinute, now),
+		outputTokens: newTokenBucket(limits.OutputTokensPerMinute, now),
+	}
+}
+
+// Wait blocks until a request using inputTokens and outputTokens can be sent within the limits.
+// The capacity is reserved when Wait returns without error.
+func (l *RateLimiter) Wait(ctx context.Context, inputTokens, outputTokens int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	inputTokens = l.inputTokens.clamp(inputTokens)
+	outputTokens = l.outputTokens.clamp(outputTokens)
+	now := time.Now()
+	delay := l.requests.reserve(1, now)
+	if inputDelay := l.inputTokens.reserve(inputTokens, now); inputDelay > delay {
+		delay = inputDelay
+	}
+	if outputDelay := l.outputTokens.reserve(outputTokens, now); outputDelay > delay {
+		delay = outputDelay
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.release(1, inputTokens, outputTokens)
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Adjust corrects the tokens reserved by Wait once the actual usage is known. Positive values
+// consume more tokens, negative values give reserved tokens back.
+func (l *RateLimiter) Adjust(inputTokens, outputTokens int) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.inputTokens.reserve(inputTokens, now)
+	l.outputTokens.reserve(outputTokens, now)
+}
+
+func (l *RateLimiter) release(requests, inputTokens, outputTokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.requests.reserve(-requests, now)
+	l.inputTokens.reserve(-inputTokens, now)
+	l.outputTokens.reserve(-outputTokens, now)
+}
+
+// rateLimitReservation is the capacity reserved for a single message request, clamped to the
+// capacity of the buckets it was taken from.
+type rateLimitReservation struct {
+	inputTokens  int
+	outputTokens int
+}
+
+// reserve waits for the capacity needed by req.
+func (l *RateLimiter) reserve(ctx context.Context, req *anthropic.MessageRequest) (rateLimitReservation, error) {
+	if l == nil {
+		return rateLimitReservation{}, nil
+	}
+
+	reservation := rateLimitReservation{
+		inputTokens:  l.inputTokens.clamp(estimateInputTokens(req)),
+		outputTokens: l.outputTokens.clamp(req.MaxTokensToSample),
+	}
+	err := l.Wait(ctx, reservation.inputTokens, reservation.outputTokens)
+	if err != nil {
+		return rateLimitReservation{}, err
+	}
+	return reservation, nil
+}
+
+// settle corrects a reservation with the usage of the response, giving back all reserved tokens
+// when the request failed.
+func (l *RateLimiter) settle(reservation rateLimitReservation, usage *anthropic.MessageUsage) {
+	if l == nil {
+		return
+	}
+
+	if usage == nil {
+		l.Adjust(-reservation.inputTokens, -reservation.outputTokens)
+		return
+	}
+
+	l.Adjust(usage.InputTokens-reservation.inputTokens, usage.OutputTokens-reservation.outputTokens)
+}
+
+// estimateInputTokens roughly estimates the input tokens of a request from the size of its text.
+func estimateInputTokens(req *anthropic.MessageRequest) int {
+	chars := len(req.SystemPrompt)
+	for _, block := range req.SystemBlocks {
+		chars += len(block.Text)
+	}
+
+	if len(req.Tools) > 0 {
+		tools, err := json.Marshal(req.Tools)
+		if err == nil {
+			chars += len(tools)
+		}
+	}
+
+	images := 0
+	for _, message := range req.Messages {
+		for _, block := range message.Content {
+			if _, ok := block.(anthropic.ImageContentBlock); ok {
+				images++
+				continue
+			}
+
+			data, err := json.Marshal(block)
+			if err == nil {
+				chars += len(data)
+			}
+		}
+	}
+
+	return chars/estimatedCharsPerToken + images*estimatedImageTokens
+}
+
+// tokenBucket refills continuously at its per minute limit up to a minute's worth of tokens.
+// Reservations may take the bucket below zero, in which case the caller waits for the refill.
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// newTokenBucket returns nil for a zero limit, nil buckets never throttle.
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		last:     now,
+	}
+}
+
+// clamp limits n to the capacity of the bucket. A single reservation larger than the bucket would
+// never fit, it waits for a full bucket instead.
+func (b *tokenBucket) clamp(n int) int {
+	if b == nil {
+		return n
+	}
+	return min(n, int(b.capacity))
+}
+
+// reserve takes n tokens from the bucket and returns how long to wait for them to be available.
+// A negative n gives tokens back.
+func (b *tokenBucket) reserve(n int, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.tokens += b.capacity * now.Sub(b.last).Minutes()
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	b.tokens -= float64(b.clamp(n))
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.capacity * float64(time.Minute))
+}
@@ -0,0 +1,118 @@
+package native
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	bucket := newTokenBucket(60, start)
+
+	if delay := bucket.reserve(60, start); delay != 0 {
+		t.Errorf("Expected no delay for a full bucket, got %v", delay)
+	}
+
+	if delay := bucket.reserve(1, start); delay != time.Second {
+		t.Errorf("Expected a second delay for an empty bucket, got %v", delay)
+	}
+
+	// refills at a token per second
+	if delay := bucket.reserve(1, start.Add(3*time.Second)); delay != 0 {
+		t.Errorf("Expected no delay after the refill, got %v", delay)
+	}
+
+	// reservations larger than the bucket wait for a full bucket
+	if delay := bucket.reserve(600, start.Add(3*time.Second)); delay != 59*time.Second {
+		t.Errorf("Expected a 59s delay, got %v", delay)
+	}
+
+	// giving tokens back never overfills the bucket
+	bucket.reserve(-1000, start.Add(3*time.Second))
+	if bucket.tokens != 60 {
+		t.Errorf("Expected a full bucket, got %v tokens", bucket.tokens)
+	}
+
+	var unlimited *tokenBucket
+	if delay := unlimited.reserve(1000, start); delay != 0 {
+		t.Errorf("Expected no delay without a limit, got %v", delay)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{RequestsPerMinute: 1})
+
+	err := limiter.Wait(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = limiter.Wait(ctx, 0, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+
+	// the cancelled request gave its reservation back
+	if limiter.requests.tokens > 0.1 || limiter.requests.tokens < -0.1 {
+		t.Errorf("Expected an empty bucket, got %v tokens", limiter.requests.tokens)
+	}
+}
+
+func TestRateLimiterSettle(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{InputTokensPerMinute: 1000, OutputTokensPerMinute: 1000})
+
+	request := &anthropic.MessageRequest{
+		MaxTokensToSample: 500,
+		Messages: []anthropic.MessagePartRequest{{
+			Role:    "user",
+			Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+		}},
+	}
+
+	reservation, err := limiter.reserve(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if reservation.outputTokens != 500 || reservation.inputTokens == 0 {
+		t.Errorf("Unexpected reservation %+v", reservation)
+	}
+
+	limiter.settle(reservation, &anthropic.MessageUsage{InputTokens: 100, OutputTokens: 20})
+
+	if tokens := limiter.inputTokens.tokens; tokens < 899 || tokens > 901 {
+		t.Errorf("Expected ~900 input tokens left, got %v", tokens)
+	}
+	if tokens := limiter.outputTokens.tokens; tokens < 979 || tokens > 981 {
+		t.Errorf("Expected ~980 output tokens left, got %v", tokens)
+	}
+}
+
+func TestRateLimiterSettleClamped(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{OutputTokensPerMinute: 1000})
+
+	request := &anthropic.MessageRequest{MaxTokensToSample: 4000}
+
+	reservation, err := limiter.reserve(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if reservation.outputTokens != 1000 {
+		t.Errorf("Expected the reservation to be clamped to 1000 output tokens, got %d", reservation.outputTokens)
+	}
+
+	// only the tokens actually taken from the bucket are given back
+	limiter.settle(reservation, &anthropic.MessageUsage{OutputTokens: 600})
+
+	if tokens := limiter.outputTokens.tokens; tokens < 399 || tokens > 401 {
+		t.Errorf("Expected ~400 output tokens left, got %v", tokens)
+	}
+}
@@ -0,0 +1,190 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/client"
+)
+
+var _ client.Middleware = (*Middleware)(nil)
+
+// WrapMessage records a span and metrics around a Message call.
+func (m *Middleware) WrapMessage(next client.MessageFunc) client.MessageFunc {
+	return func(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+		ctx, call := m.start(ctx, req)
+
+		response, err := next(ctx, req)
+		if err != nil {
+			call.fail(err)
+			return nil, err
+		}
+
+		call.observeResponse(response)
+		call.end()
+
+		return response, nil
+	}
+}
+
+// WrapMessageStream records a span and metrics around a MessageStream call. The span ends when the
+// stream does, and records the time to first token and inter token latency of the stream.
+func (m *Middleware) WrapMessageStream(next client.MessageStreamFunc) client.MessageStreamFunc {
+	return func(ctx context.Context, req *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+		ctx, call := m.start(ctx, req)
+		msCh, errCh := next(ctx, req)
+
+		outMsCh := make(chan *anthropic.MessageStreamResponse)
+		outErrCh := make(chan error, 1)
+
+		go func() {
+			defer close(outMsCh)
+			defer close(outErrCh)
+
+			accumulator := anthropic.NewMessageStreamAccumulator()
+			lastDelta := time.Time{}
+			abandoned := false
+
+			for event := range msCh {
+				if event != nil && event.Type == string(anthropic.MessageEventTypeContentBlockDelta) {
+					now := time.Now()
+					if lastDelta.IsZero() {
+						call.observeFirstToken(now.Sub(call.startTime))
+					} else {
+						m.interTokenLatency.Record(ctx, now.Sub(lastDelta).Seconds(), call.metricAttributes)
+					}
+					lastDelta = now
+				}
+				_ = accumulator.Add(event)
+
+				// keep draining the stream once the caller has gone, so the client isn't blocked
+				if abandoned {
+					continue
+				}
+				select {
+				case outMsCh <- event:
+				case <-ctx.Done():
+					abandoned = true
+				}
+			}
+
+			err := <-errCh
+			if err != nil {
+				call.fail(err)
+				outErrCh <- err
+				return
+			}
+
+			call.observeResponse(accumulator.Response())
+			call.end()
+		}()
+
+		return outMsCh, outErrCh
+	}
+}
+
+// call holds the state of a single instrumented call.
+type call struct {
+	middleware       *Middleware
+	ctx              context.Context
+	span             trace.Span
+	startTime        time.Time
+	metricAttributes metric.MeasurementOption
+	attributes       []attribute.KeyValue
+}
+
+func (m *Middleware) start(ctx context.Context, req *anthropic.MessageRequest) (context.Context, *call) {
+	attributes := []attribute.KeyValue{
+		attribute.String("gen_ai.operation.name", "chat"),
+		attribute.String("gen_ai.system", m.system),
+		attribute.String("gen_ai.request.model", string(req.Model)),
+	}
+
+	spanAttributes := append([]attribute.KeyValue{}, attributes...)
+	spanAttributes = append(spanAttributes, attribute.Int("gen_ai.request.max_tokens", req.MaxTokensToSample))
+	if req.Temperature != 0 {
+		spanAttributes = append(spanAttributes, attribute.Float64("gen_ai.request.temperature", req.Temperature))
+	}
+	if req.TopP != 0 {
+		spanAttributes = append(spanAttributes, attribute.Float64("gen_ai.request.top_p", req.TopP))
+	}
+	if req.TopK != 0 {
+		spanAttributes = append(spanAttributes, attribute.Int("gen_ai.request.top_k", req.TopK))
+	}
+	if len(req.StopSequences) > 0 {
+		spanAttributes = append(spanAttributes, attribute.StringSlice("gen_ai.request.stop_sequences", req.StopSequences))
+	}
+
+	ctx, span := m.tracer.Start(ctx, "chat "+string(req.Model),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(spanAttributes...),
+	)
+
+	return ctx, &call{
+		middleware:       m,
+		ctx:              ctx,
+		span:             span,
+		startTime:        time.Now(),
+		metricAttributes: metric.WithAttributes(attributes...),
+		attributes:       attributes,
+	}
+}
+
+func (c *call) observeFirstToken(latency time.Duration) {
+	c.middleware.timeToFirstToken.Record(c.ctx, latency.Seconds(), c.metricAttributes)
+	c.span.SetAttributes(attribute.Float64("gen_ai.response.time_to_first_token", latency.Seconds()))
+}
+
+func (c *call) observeResponse(response *anthropic.MessageResponse) {
+	c.span.SetAttributes(
+		attribute.String("gen_ai.response.id", response.ID),
+		attribute.String("gen_ai.response.model", response.Model),
+		attribute.StringSlice("gen_ai.response.finish_reasons", []string{response.StopReason}),
+		attribute.Int("gen_ai.usage.input_tokens", response.Usage.InputTokens),
+		attribute.Int("gen_ai.usage.output_tokens", response.Usage.OutputTokens),
+	)
+	if response.RequestID != "" {
+		c.span.SetAttributes(attribute.String("anthropic.request_id", response.RequestID))
+	}
+
+	c.recordTokens("input", response.Usage.InputTokens)
+	c.recordTokens("output", response.Usage.OutputTokens)
+}
+
+func (c *call) recordTokens(tokenType string, tokens int) {
+	attributes := append([]attribute.KeyValue{}, c.attributes...)
+	attributes = append(attributes, attribute.String("gen_ai.token.type", tokenType))
+	c.middleware.tokenUsage.Record(c.ctx, int64(tokens), metric.WithAttributes(attributes...))
+}
+
+func (c *call) fail(err error) {
+	errType := errorType(err)
+
+	attributes := append([]attribute.KeyValue{}, c.attributes...)
+	attributes = append(attributes, attribute.String("error.type", errType))
+
+	var apiErr *anthropic.APIError
+	if errors.As(err, &apiErr) && apiErr.RequestID != "" {
+		c.span.SetAttributes(attribute.String("anthropic.request_id", apiErr.RequestID))
+	}
+
+	c.span.SetAttributes(attribute.String("error.type", errType))
+	c.span.RecordError(err)
+	c.span.SetStatus(codes.Error, err.Error())
+
+	c.middleware.errors.Add(c.ctx, 1, metric.WithAttributes(attributes...))
+	c.middleware.operationDuration.Record(c.ctx, time.Since(c.startTime).Seconds(), metric.WithAttributes(attributes...))
+	c.span.End()
+}
+
+func (c *call) end() {
+	c.middleware.operationDuration.Record(c.ctx, time.Since(c.startTime).Seconds(), c.metricAttributes)
+	c.span.End()
+}
@@ -0,0 +1,131 @@
+// Package telemetry instruments clients with OpenTelemetry traces and metrics following the GenAI
+// semantic conventions. It is provided as a client.Middleware, so it works the same way for the
+// native and Bedrock clients:
+//
+//	mw, err := telemetry.MakeMiddleware(telemetry.Config{System: telemetry.SystemAnthropic})
+//	c := client.Wrap(nativeClient, mw)
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+const instrumentationName = "github.com/madebywelch/anthropic-go/v4/pkg/anthropic/client/telemetry"
+
+// Values of the gen_ai.system attribute.
+const (
+	SystemAnthropic  = "anthropic"
+	SystemAWSBedrock = "aws.bedrock"
+)
+
+type Config struct {
+	// Optional (defaults to SystemAnthropic), the gen_ai.system attribute of the telemetry.
+	System string
+	// Optional (defaults to the global TracerProvider)
+	TracerProvider trace.TracerProvider
+	// Optional (defaults to the global MeterProvider)
+	MeterProvider metric.MeterProvider
+}
+
+// Middleware records a span and metrics for every Message and MessageStream call.
+type Middleware struct {
+	system string
+	tracer trace.Tracer
+
+	operationDuration metric.Float64Histogram
+	tokenUsage        metric.Int64Histogram
+	timeToFirstToken  metric.Float64Histogram
+	interTokenLatency metric.Float64Histogram
+	errors            metric.Int64Counter
+}
+
+func MakeMiddleware(cfg Config) (*Middleware, error) {
+	if cfg.System == "" {
+		cfg.System = SystemAnthropic
+	}
+
+	if cfg.TracerProvider == nil {
+		cfg.TracerProvider = otel.GetTracerProvider()
+	}
+
+	if cfg.MeterProvider == nil {
+		cfg.MeterProvider = otel.GetMeterProvider()
+	}
+
+	meter := cfg.MeterProvider.Meter(instrumentationName)
+	m := &Middleware{
+		system: cfg.System,
+		tracer: cfg.TracerProvider.Tracer(instrumentationName),
+	}
+
+	var err error
+	m.operationDuration, err = meter.Float64Histogram(
+		"gen_ai.client.operation.duration",
+		metric.WithDescription("Duration of the GenAI operations"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating operation duration histogram: %w", err)
+	}
+
+	m.tokenUsage, err = meter.Int64Histogram(
+		"gen_ai.client.token.usage",
+		metric.WithDescription("Number of input and output tokens used"),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating token usage histogram: %w", err)
+	}
+
+	m.timeToFirstToken, err = meter.Float64Histogram(
+		"gen_ai.client.time_to_first_token",
+		metric.WithDescription("Time from sending a streaming request to receiving the first content delta"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating time to first token histogram: %w", err)
+	}
+
+	m.interTokenLatency, err = meter.Float64Histogram(
+		"gen_ai.client.inter_token_latency",
+		metric.WithDescription("Time between consecutive content deltas of a stream"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating inter token latency histogram: %w", err)
+	}
+
+	m.errors, err = meter.Int64Counter(
+		"gen_ai.client.errors",
+		metric.WithDescription("Number of failed GenAI operations"),
+		metric.WithUnit("{error}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating error counter: %w", err)
+	}
+
+	return m, nil
+}
+
+// errorType returns the error.type attribute of err: the API error type or status code when known,
+// _OTHER otherwise.
+func errorType(err error) string {
+	var apiErr *anthropic.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Type != "" {
+			return apiErr.Type
+		}
+		if apiErr.StatusCode != 0 {
+			return strconv.Itoa(apiErr.StatusCode)
+		}
+	}
+	return "_OTHER"
+}
@@ -0,0 +1,237 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/client"
+)
+
+type fakeClient struct {
+	response *anthropic.MessageResponse
+	events   []*anthropic.MessageStreamResponse
+	err      error
+}
+
+func (f *fakeClient) Message(ctx context.Context, req *anthropic.MessageRequest) (*anthropic.MessageResponse, error) {
+	return f.response, f.err
+}
+
+func (f *fakeClient) MessageStream(ctx context.Context, req *anthropic.MessageRequest) (<-chan *anthropic.MessageStreamResponse, <-chan error) {
+	msCh := make(chan *anthropic.MessageStreamResponse)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(msCh)
+		defer close(errCh)
+
+		for _, event := range f.events {
+			msCh <- event
+		}
+		if f.err != nil {
+			errCh <- f.err
+		}
+	}()
+
+	return msCh, errCh
+}
+
+func (f *fakeClient) CountTokens(ctx context.Context, req *anthropic.MessageRequest) (int, error) {
+	return 0, f.err
+}
+
+type testTelemetry struct {
+	spans   *tracetest.SpanRecorder
+	metrics *sdkmetric.ManualReader
+}
+
+func setup(t *testing.T, fake *fakeClient) (client.Client, testTelemetry) {
+	telemetry := testTelemetry{
+		spans:   tracetest.NewSpanRecorder(),
+		metrics: sdkmetric.NewManualReader(),
+	}
+
+	middleware, err := MakeMiddleware(Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(telemetry.spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(telemetry.metrics)),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return client.Wrap(fake, middleware), telemetry
+}
+
+func (tt testTelemetry) metric(t *testing.T, name string) metricdata.Metrics {
+	data := metricdata.ResourceMetrics{}
+	err := tt.metrics.Collect(context.Background(), &data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+
+	t.Fatalf("metric %s not recorded", name)
+	return metricdata.Metrics{}
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+var request = &anthropic.MessageRequest{
+	Model:             anthropic.Claude35Sonnet,
+	MaxTokensToSample: 256,
+	Messages: []anthropic.MessagePartRequest{{
+		Role:    "user",
+		Content: []anthropic.ContentBlock{anthropic.NewTextContentBlock("Hello")},
+	}},
+}
+
+func TestMessageTelemetry(t *testing.T) {
+	c, telemetry := setup(t, &fakeClient{response: &anthropic.MessageResponse{
+		ID:         "msg_1",
+		Model:      "claude-3-5-sonnet-20241022",
+		StopReason: "end_turn",
+		Usage:      anthropic.MessageUsage{InputTokens: 12, OutputTokens: 34},
+		RequestID:  "req_1",
+	}})
+
+	_, err := c.Message(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := telemetry.spans.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	if spans[0].Name() != "chat claude-3-5-sonnet-latest" {
+		t.Errorf("unexpected span name %q", spans[0].Name())
+	}
+
+	attributes := spanAttributes(spans[0])
+	expected := map[attribute.Key]attribute.Value{
+		"gen_ai.system":              attribute.StringValue("anthropic"),
+		"gen_ai.request.model":       attribute.StringValue("claude-3-5-sonnet-latest"),
+		"gen_ai.request.max_tokens":  attribute.IntValue(256),
+		"gen_ai.response.id":         attribute.StringValue("msg_1"),
+		"gen_ai.usage.input_tokens":  attribute.IntValue(12),
+		"gen_ai.usage.output_tokens": attribute.IntValue(34),
+		"anthropic.request_id":       attribute.StringValue("req_1"),
+	}
+	for key, value := range expected {
+		if attributes[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value.Emit(), attributes[key].Emit())
+		}
+	}
+
+	tokens := telemetry.metric(t, "gen_ai.client.token.usage").Data.(metricdata.Histogram[int64])
+	total := int64(0)
+	for _, point := range tokens.DataPoints {
+		total += point.Sum
+	}
+	if total != 46 {
+		t.Errorf("expected 46 tokens recorded, got %d", total)
+	}
+}
+
+func TestMessageTelemetryError(t *testing.T) {
+	apiErr := &anthropic.APIError{StatusCode: 529, Type: "overloaded_error", RequestID: "req_2"}
+	c, telemetry := setup(t, &fakeClient{err: apiErr})
+
+	_, err := c.Message(context.Background(), request)
+	if !errors.Is(err, anthropic.ErrAnthropicOverloaded) {
+		t.Fatalf("expected the client's error, got %v", err)
+	}
+
+	spans := telemetry.spans.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Fatalf("expected a failed span, got %+v", spans)
+	}
+
+	if spanAttributes(spans[0])["error.type"].AsString() != "overloaded_error" {
+		t.Errorf("unexpected error.type %v", spanAttributes(spans[0])["error.type"].Emit())
+	}
+
+	errorsMetric := telemetry.metric(t, "gen_ai.client.errors").Data.(metricdata.Sum[int64])
+	if len(errorsMetric.DataPoints) != 1 || errorsMetric.DataPoints[0].Value != 1 {
+		t.Errorf("expected 1 error recorded, got %+v", errorsMetric.DataPoints)
+	}
+
+	errType, _ := errorsMetric.DataPoints[0].Attributes.Value("error.type")
+	if errType.AsString() != "overloaded_error" {
+		t.Errorf("unexpected error.type %v", errType.Emit())
+	}
+}
+
+func TestMessageStreamTelemetry(t *testing.T) {
+	c, telemetry := setup(t, &fakeClient{events: []*anthropic.MessageStreamResponse{
+		{
+			Type:      "message_start",
+			Message:   &anthropic.MessageResponse{ID: "msg_3", Model: "claude-3-5-sonnet-20241022"},
+			Usage:     anthropic.MessageStreamUsage{InputTokens: 5},
+			RequestID: "req_3",
+		},
+		{Type: "content_block_start", ContentBlock: &anthropic.MessageStreamContentBlock{Type: "text"}},
+		{Type: "content_block_delta", Delta: anthropic.MessageStreamDelta{Type: "text_delta", Text: "Hel"}},
+		{Type: "content_block_delta", Delta: anthropic.MessageStreamDelta{Type: "text_delta", Text: "lo"}},
+		{Type: "content_block_delta", Delta: anthropic.MessageStreamDelta{Type: "text_delta", Text: "!"}},
+		{Type: "content_block_stop"},
+		{Type: "message_delta", Delta: anthropic.MessageStreamDelta{StopReason: "end_turn"}, Usage: anthropic.MessageStreamUsage{OutputTokens: 3}},
+		{Type: "message_stop"},
+	}})
+
+	msCh, errCh := c.MessageStream(context.Background(), request)
+	response, err := anthropic.AccumulateMessageStream(context.Background(), msCh, errCh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Text() != "Hello!" {
+		t.Errorf("expected the stream to be forwarded, got %q", response.Text())
+	}
+
+	spans := telemetry.spans.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	attributes := spanAttributes(spans[0])
+	if attributes["gen_ai.usage.output_tokens"].AsInt64() != 3 || attributes["anthropic.request_id"].AsString() != "req_3" {
+		t.Errorf("unexpected span attributes %v", attributes)
+	}
+	if _, ok := attributes["gen_ai.response.time_to_first_token"]; !ok {
+		t.Error("expected the time to first token on the span")
+	}
+
+	firstToken := telemetry.metric(t, "gen_ai.client.time_to_first_token").Data.(metricdata.Histogram[float64])
+	if len(firstToken.DataPoints) != 1 || firstToken.DataPoints[0].Count != 1 {
+		t.Errorf("expected 1 time to first token, got %+v", firstToken.DataPoints)
+	}
+
+	interToken := telemetry.metric(t, "gen_ai.client.inter_token_latency").Data.(metricdata.Histogram[float64])
+	if len(interToken.DataPoints) != 1 || interToken.DataPoints[0].Count != 2 {
+		t.Errorf("expected 2 inter token latencies, got %+v", interToken.DataPoints)
+	}
+}
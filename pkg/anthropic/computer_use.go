@@ -0,0 +1,322 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Beta features enabling the Anthropic-defined computer use tools, one per version of the tools.
+// The clients send the beta matching the tools of a request automatically.
+const (
+	ComputerUseBeta          = "computer-use-2024-10-22"
+	ComputerUseBeta_20250124 = "computer-use-2025-01-24"
+)
+
+// Versioned types of the Anthropic-defined tools. The 20241022 tools are supported by Claude 3.5
+// Sonnet, the 20250124 tools by Claude 3.7 Sonnet.
+const (
+	ToolTypeComputer   = "computer_20241022"
+	ToolTypeTextEditor = "text_editor_20241022"
+	ToolTypeBash       = "bash_20241022"
+
+	ToolTypeComputer_20250124   = "computer_20250124"
+	ToolTypeTextEditor_20250124 = "text_editor_20250124"
+	ToolTypeBash_20250124       = "bash_20250124"
+)
+
+// Names the API requires for the Anthropic-defined tools, also used in their tool_use blocks.
+const (
+	ToolNameComputer   = "computer"
+	ToolNameTextEditor = "str_replace_editor"
+	ToolNameBash       = "bash"
+)
+
+// builtinTool describes a version of an Anthropic-defined tool.
+type builtinTool struct {
+	name string
+	beta string
+}
+
+// builtinTools maps the type of each Anthropic-defined tool to its required name and beta.
+var builtinTools = map[string]builtinTool{
+	ToolTypeComputer:   {name: ToolNameComputer, beta: ComputerUseBeta},
+	ToolTypeTextEditor: {name: ToolNameTextEditor, beta: ComputerUseBeta},
+	ToolTypeBash:       {name: ToolNameBash, beta: ComputerUseBeta},
+
+	ToolTypeComputer_20250124:   {name: ToolNameComputer, beta: ComputerUseBeta_20250124},
+	ToolTypeTextEditor_20250124: {name: ToolNameTextEditor, beta: ComputerUseBeta_20250124},
+	ToolTypeBash_20250124:       {name: ToolNameBash, beta: ComputerUseBeta_20250124},
+}
+
+// NewComputerTool creates the 20241022 computer tool for a display of width by height pixels.
+// displayNum is the X11 display number, 0 to leave it out.
+func NewComputerTool(width, height, displayNum int) Tool {
+	return newComputerTool(ToolTypeComputer, width, height, displayNum)
+}
+
+// NewComputerTool_20250124 creates the 20250124 computer tool, see NewComputerTool.
+func NewComputerTool_20250124(width, height, displayNum int) Tool {
+	return newComputerTool(ToolTypeComputer_20250124, width, height, displayNum)
+}
+
+func newComputerTool(toolType string, width, height, displayNum int) Tool {
+	return Tool{
+		Type:            toolType,
+		Name:            ToolNameComputer,
+		DisplayWidthPx:  width,
+		DisplayHeightPx: height,
+		DisplayNumber:   displayNum,
+	}
+}
+
+// NewTextEditorTool creates the 20241022 str_replace_editor tool for viewing and editing files.
+func NewTextEditorTool() Tool {
+	return Tool{Type: ToolTypeTextEditor, Name: ToolNameTextEditor}
+}
+
+// NewTextEditorTool_20250124 creates the 20250124 str_replace_editor tool.
+func NewTextEditorTool_20250124() Tool {
+	return Tool{Type: ToolTypeTextEditor_20250124, Name: ToolNameTextEditor}
+}
+
+// NewBashTool creates the 20241022 bash tool for running shell commands.
+func NewBashTool() Tool {
+	return Tool{Type: ToolTypeBash, Name: ToolNameBash}
+}
+
+// NewBashTool_20250124 creates the 20250124 bash tool.
+func NewBashTool_20250124() Tool {
+	return Tool{Type: ToolTypeBash_20250124, Name: ToolNameBash}
+}
+
+// IsBuiltin reports whether the tool is defined by Anthropic rather than by its InputSchema.
+func (t Tool) IsBuiltin() bool {
+	return t.Type != "" && t.Type != "custom"
+}
+
+// MarshalJSON leaves out the input schema of Anthropic-defined tools, which the API rejects.
+func (t Tool) MarshalJSON() ([]byte, error) {
+	type alias Tool
+	if !t.IsBuiltin() {
+		return json.Marshal(alias(t))
+	}
+
+	return json.Marshal(struct {
+		alias
+		InputSchema *InputSchema `json:"input_schema,omitempty"`
+	}{
+		alias: alias(t),
+	})
+}
+
+// ContainsComputerUseTools reports whether the request declares an Anthropic-defined tool.
+func (m *MessageRequest) ContainsComputerUseTools() bool {
+	for _, tool := range m.Tools {
+		if tool.IsBuiltin() {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputerUseBetas returns the beta features required by the Anthropic-defined tools of the
+// request, each listed once.
+func (m *MessageRequest) ComputerUseBetas() []string {
+	betas := []string{}
+	for _, tool := range m.Tools {
+		builtin, ok := builtinTools[tool.Type]
+		if ok && !slices.Contains(betas, builtin.beta) {
+			betas = append(betas, builtin.beta)
+		}
+	}
+	return betas
+}
+
+// validateBuiltinTools checks the Anthropic-defined tools of req are supported by its model, named
+// as the API requires, and that the computer tool has a display size.
+func validateBuiltinTools(req *MessageRequest, capabilities ModelCapabilities) error {
+	for _, tool := range req.Tools {
+		builtin, ok := builtinTools[tool.Type]
+		if !ok {
+			continue
+		}
+
+		if builtin.beta != capabilities.ComputerUseBeta && !capabilities.Discovered {
+			return fmt.Errorf("model %s does not support tool type %s", req.Model, tool.Type)
+		}
+
+		if tool.Name != builtin.name {
+			return fmt.Errorf("tool of type %s must be named %s, got %s", tool.Type, builtin.name, tool.Name)
+		}
+
+		if builtin.name == ToolNameComputer && (tool.DisplayWidthPx <= 0 || tool.DisplayHeightPx <= 0) {
+			return fmt.Errorf("computer tool requires a display size, got %dx%d", tool.DisplayWidthPx, tool.DisplayHeightPx)
+		}
+	}
+
+	return nil
+}
+
+// Actions of the computer tool.
+const (
+	ComputerActionKey            = "key"
+	ComputerActionType           = "type"
+	ComputerActionMouseMove      = "mouse_move"
+	ComputerActionLeftClick      = "left_click"
+	ComputerActionLeftClickDrag  = "left_click_drag"
+	ComputerActionRightClick     = "right_click"
+	ComputerActionMiddleClick    = "middle_click"
+	ComputerActionDoubleClick    = "double_click"
+	ComputerActionScreenshot     = "screenshot"
+	ComputerActionCursorPosition = "cursor_position"
+
+	// actions added by the 20250124 computer tool
+	ComputerActionTripleClick   = "triple_click"
+	ComputerActionLeftMouseDown = "left_mouse_down"
+	ComputerActionLeftMouseUp   = "left_mouse_up"
+	ComputerActionScroll        = "scroll"
+	ComputerActionHoldKey       = "hold_key"
+	ComputerActionWait          = "wait"
+)
+
+// ComputerInput is the input of a computer tool_use block.
+type ComputerInput struct {
+	Action string `json:"action"`
+	// Coordinate is the x and y position in pixels of mouse_move, left_click_drag and scroll
+	// actions, and optionally of clicks with the 20250124 tool.
+	Coordinate []int `json:"coordinate,omitempty"`
+	// Text is the key combination of key and hold_key actions, the text of type actions, and the
+	// modifier keys held during clicks and scrolls with the 20250124 tool.
+	Text string `json:"text,omitempty"`
+	// ScrollDirection is up, down, left or right for scroll actions.
+	ScrollDirection string `json:"scroll_direction,omitempty"`
+	// ScrollAmount is the number of scroll wheel clicks of scroll actions.
+	ScrollAmount int `json:"scroll_amount,omitempty"`
+	// Duration is the number of seconds of hold_key and wait actions.
+	Duration float64 `json:"duration,omitempty"`
+}
+
+// DecodeComputerInput decodes the input of a computer tool_use block, checking the action has the
+// parameters it requires.
+func DecodeComputerInput(input interface{}) (*ComputerInput, error) {
+	computerInput := &ComputerInput{}
+	err := DecodeToolInput(input, computerInput)
+	if err != nil {
+		return nil, err
+	}
+
+	switch computerInput.Action {
+	case ComputerActionKey, ComputerActionType, ComputerActionHoldKey:
+		if computerInput.Text == "" {
+			return nil, fmt.Errorf("text is required for computer action %s", computerInput.Action)
+		}
+	case ComputerActionMouseMove, ComputerActionLeftClickDrag:
+		if len(computerInput.Coordinate) != 2 {
+			return nil, fmt.Errorf("coordinate must be an [x, y] pair for computer action %s", computerInput.Action)
+		}
+	case ComputerActionScroll:
+		if len(computerInput.Coordinate) != 2 {
+			return nil, fmt.Errorf("coordinate must be an [x, y] pair for computer action %s", computerInput.Action)
+		}
+		switch computerInput.ScrollDirection {
+		case "up", "down", "left", "right":
+		default:
+			return nil, fmt.Errorf("unknown scroll direction %q", computerInput.ScrollDirection)
+		}
+	case ComputerActionLeftClick, ComputerActionRightClick, ComputerActionMiddleClick,
+		ComputerActionDoubleClick, ComputerActionTripleClick, ComputerActionLeftMouseDown,
+		ComputerActionLeftMouseUp:
+		if computerInput.Coordinate != nil && len(computerInput.Coordinate) != 2 {
+			return nil, fmt.Errorf("coordinate must be an [x, y] pair for computer action %s", computerInput.Action)
+		}
+	case ComputerActionScreenshot, ComputerActionCursorPosition, ComputerActionWait:
+	default:
+		return nil, fmt.Errorf("unknown computer action %q", computerInput.Action)
+	}
+
+	return computerInput, nil
+}
+
+// Commands of the str_replace_editor tool.
+const (
+	TextEditorCommandView       = "view"
+	TextEditorCommandCreate     = "create"
+	TextEditorCommandStrReplace = "str_replace"
+	TextEditorCommandInsert     = "insert"
+	TextEditorCommandUndoEdit   = "undo_edit"
+)
+
+// TextEditorInput is the input of a str_replace_editor tool_use block.
+type TextEditorInput struct {
+	Command string `json:"command"`
+	// Path is the absolute path of the file or directory.
+	Path string `json:"path"`
+	// FileText is the content of the file of create commands.
+	FileText string `json:"file_text,omitempty"`
+	// ViewRange is the optional [start, end] range of lines of view commands, 1-indexed; an end of -1
+	// shows the rest of the file.
+	ViewRange []int `json:"view_range,omitempty"`
+	// OldStr is the text replaced by str_replace commands, it must appear exactly once in the file.
+	OldStr string `json:"old_str,omitempty"`
+	// NewStr is the replacement of str_replace commands and the text inserted by insert commands.
+	NewStr string `json:"new_str,omitempty"`
+	// InsertLine is the line after which insert commands insert NewStr, 0 for the start of the file.
+	InsertLine *int `json:"insert_line,omitempty"`
+}
+
+// DecodeTextEditorInput decodes the input of a str_replace_editor tool_use block, checking the
+// command has the parameters it requires.
+func DecodeTextEditorInput(input interface{}) (*TextEditorInput, error) {
+	editorInput := &TextEditorInput{}
+	err := DecodeToolInput(input, editorInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if editorInput.Path == "" {
+		return nil, fmt.Errorf("path is required for text editor command %s", editorInput.Command)
+	}
+
+	switch editorInput.Command {
+	case TextEditorCommandView:
+		if editorInput.ViewRange != nil && len(editorInput.ViewRange) != 2 {
+			return nil, fmt.Errorf("view_range must be a [start, end] pair")
+		}
+	case TextEditorCommandCreate, TextEditorCommandUndoEdit:
+	case TextEditorCommandStrReplace:
+		if editorInput.OldStr == "" {
+			return nil, fmt.Errorf("old_str is required for text editor command %s", editorInput.Command)
+		}
+	case TextEditorCommandInsert:
+		if editorInput.InsertLine == nil {
+			return nil, fmt.Errorf("insert_line is required for text editor command %s", editorInput.Command)
+		}
+	default:
+		return nil, fmt.Errorf("unknown text editor command %q", editorInput.Command)
+	}
+
+	return editorInput, nil
+}
+
+// BashInput is the input of a bash tool_use block: either a command to run or a request to restart
+// the shell.
+type BashInput struct {
+	Command string `json:"command,omitempty"`
+	Restart bool   `json:"restart,omitempty"`
+}
+
+// DecodeBashInput decodes the input of a bash tool_use block.
+func DecodeBashInput(input interface{}) (*BashInput, error) {
+	bashInput := &BashInput{}
+	err := DecodeToolInput(input, bashInput)
+	if err != nil {
+		return nil, err
+	}
+
+	if bashInput.Command == "" && !bashInput.Restart {
+		return nil, fmt.Errorf("command is required unless restarting the shell")
+	}
+
+	return bashInput, nil
+}
@@ -0,0 +1,158 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBuiltinToolJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     Tool
+		expected string
+	}{
+		{"computer", NewComputerTool(1024, 768, 1), `{"type":"computer_20241022","name":"computer","display_width_px":1024,"display_height_px":768,"display_number":1}`},
+		{"text editor", NewTextEditorTool(), `{"type":"text_editor_20241022","name":"str_replace_editor"}`},
+		{"bash", NewBashTool(), `{"type":"bash_20241022","name":"bash"}`},
+		{"computer 20250124", NewComputerTool_20250124(1280, 800, 0), `{"type":"computer_20250124","name":"computer","display_width_px":1280,"display_height_px":800}`},
+		{"text editor 20250124", NewTextEditorTool_20250124(), `{"type":"text_editor_20250124","name":"str_replace_editor"}`},
+		{"bash 20250124", NewBashTool_20250124(), `{"type":"bash_20250124","name":"bash"}`},
+		{"custom", Tool{Name: "get_weather", InputSchema: InputSchema{Type: "object"}}, `{"name":"get_weather","input_schema":{"type":"object","properties":null}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.tool)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("got %s, want %s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateBuiltinTools(t *testing.T) {
+	request := func(model Model, tools ...Tool) *MessageRequest {
+		return &MessageRequest{
+			Model:    model,
+			Tools:    tools,
+			Messages: []MessagePartRequest{{Role: "user", Content: []ContentBlock{NewTextContentBlock("Hello")}}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		request *MessageRequest
+		expErr  string
+	}{
+		{"supported", request(Claude35Sonnet, NewComputerTool(1024, 768, 0), NewTextEditorTool(), NewBashTool()), ""},
+		{"supported 20250124", request(Claude37Sonnet, NewComputerTool_20250124(1024, 768, 0), NewTextEditorTool_20250124(), NewBashTool_20250124()), ""},
+		{"unsupported model", request(Claude3Opus, NewBashTool()), "model claude-3-opus-20240229 does not support computer use"},
+		{"older tool version", request(Claude37Sonnet, NewBashTool()), "model claude-3-7-sonnet-latest does not support tool type bash_20241022"},
+		{"newer tool version", request(Claude35Sonnet, NewBashTool_20250124()), "model claude-3-5-sonnet-latest does not support tool type bash_20250124"},
+		{"renamed tool", request(Claude35Sonnet, Tool{Type: ToolTypeBash, Name: "shell"}), "tool of type bash_20241022 must be named bash, got shell"},
+		{"no display size", request(Claude35Sonnet, NewComputerTool(0, 0, 0)), "computer tool requires a display size, got 0x0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessageRequest(tt.request)
+			if tt.expErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.expErr {
+				t.Errorf("expected error %s, got %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestDecodeComputerInput(t *testing.T) {
+	input, err := DecodeComputerInput(map[string]interface{}{"action": "left_click_drag", "coordinate": []interface{}{10.0, 20.0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if input.Action != ComputerActionLeftClickDrag || input.Coordinate[0] != 10 || input.Coordinate[1] != 20 {
+		t.Errorf("unexpected input %+v", input)
+	}
+
+	_, err = DecodeComputerInput(json.RawMessage(`{"action":"type"}`))
+	if err == nil || err.Error() != "text is required for computer action type" {
+		t.Errorf("expected a missing text error, got %v", err)
+	}
+
+	input, err = DecodeComputerInput(json.RawMessage(`{"action":"scroll","coordinate":[5,5],"scroll_direction":"down","scroll_amount":3,"text":"shift"}`))
+	if err != nil || input.ScrollDirection != "down" || input.ScrollAmount != 3 || input.Text != "shift" {
+		t.Errorf("unexpected scroll input %+v, %v", input, err)
+	}
+
+	_, err = DecodeComputerInput(json.RawMessage(`{"action":"scroll","coordinate":[5,5],"scroll_direction":"sideways"}`))
+	if err == nil || err.Error() != `unknown scroll direction "sideways"` {
+		t.Errorf("expected an unknown direction error, got %v", err)
+	}
+
+	_, err = DecodeComputerInput(json.RawMessage(`{"action":"zoom"}`))
+	if err == nil || err.Error() != `unknown computer action "zoom"` {
+		t.Errorf("expected an unknown action error, got %v", err)
+	}
+}
+
+func TestDecodeTextEditorInput(t *testing.T) {
+	input, err := DecodeTextEditorInput(json.RawMessage(`{"command":"insert","path":"/tmp/a.txt","insert_line":0,"new_str":"hello"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if input.InsertLine == nil || *input.InsertLine != 0 || input.NewStr != "hello" {
+		t.Errorf("unexpected input %+v", input)
+	}
+
+	tests := []struct {
+		input  string
+		expErr string
+	}{
+		{`{"command":"view"}`, "path is required for text editor command view"},
+		{`{"command":"view","path":"/tmp","view_range":[1]}`, "view_range must be a [start, end] pair"},
+		{`{"command":"str_replace","path":"/tmp/a.txt","new_str":"b"}`, "old_str is required for text editor command str_replace"},
+		{`{"command":"insert","path":"/tmp/a.txt","new_str":"b"}`, "insert_line is required for text editor command insert"},
+		{`{"command":"delete","path":"/tmp/a.txt"}`, `unknown text editor command "delete"`},
+	}
+
+	for _, tt := range tests {
+		_, err := DecodeTextEditorInput(json.RawMessage(tt.input))
+		if err == nil || err.Error() != tt.expErr {
+			t.Errorf("%s: expected error %s, got %v", tt.input, tt.expErr, err)
+		}
+	}
+}
+
+func TestDecodeBashInput(t *testing.T) {
+	input, err := DecodeBashInput(map[string]interface{}{"restart": true})
+	if err != nil || !input.Restart {
+		t.Errorf("expected a restart, got %+v, %v", input, err)
+	}
+
+	_, err = DecodeBashInput(map[string]interface{}{})
+	if err == nil {
+		t.Error("expected an error for an empty input")
+	}
+}
+
+func TestComputerUseBetas(t *testing.T) {
+	request := &MessageRequest{Tools: []Tool{{Name: "get_weather"}, NewComputerTool_20250124(1024, 768, 0), NewBashTool_20250124()}}
+	if betas := request.ComputerUseBetas(); !reflect.DeepEqual(betas, []string{ComputerUseBeta_20250124}) {
+		t.Errorf("expected the 20250124 beta once, got %v", betas)
+	}
+
+	request.Tools = []Tool{{Name: "get_weather"}}
+	if betas := request.ComputerUseBetas(); len(betas) != 0 {
+		t.Errorf("expected no betas, got %v", betas)
+	}
+}
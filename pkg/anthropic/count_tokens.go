@@ -0,0 +1,53 @@
+package anthropic
+
+import "encoding/json"
+
+// CountTokensRequest is the request to the Anthropic API for counting the input tokens of a
+// message request. It holds the fields of the MessageRequest that make up the prompt.
+type CountTokensRequest struct {
+	Model        Model
+	Tools        []Tool
+	Messages     []MessagePartRequest
+	SystemPrompt string
+	SystemBlocks []TextContentBlock
+	ToolChoice   *ToolChoice
+	Thinking     *ThinkingConfig
+}
+
+// NewCountTokensRequest creates the CountTokensRequest of req.
+func NewCountTokensRequest(req *MessageRequest) *CountTokensRequest {
+	return &CountTokensRequest{
+		Model:        req.Model,
+		Tools:        req.Tools,
+		Messages:     req.Messages,
+		SystemPrompt: req.SystemPrompt,
+		SystemBlocks: req.SystemBlocks,
+		ToolChoice:   req.ToolChoice,
+		Thinking:     req.Thinking,
+	}
+}
+
+// MarshalJSON encodes the system prompt the same way as MessageRequest.
+func (c CountTokensRequest) MarshalJSON() ([]byte, error) {
+	req := MessageRequest{SystemPrompt: c.SystemPrompt, SystemBlocks: c.SystemBlocks}
+	return json.Marshal(struct {
+		Model      Model                `json:"model"`
+		System     interface{}          `json:"system,omitempty"`
+		Tools      []Tool               `json:"tools,omitempty"`
+		Messages   []MessagePartRequest `json:"messages"`
+		ToolChoice *ToolChoice          `json:"tool_choice,omitempty"`
+		Thinking   *ThinkingConfig      `json:"thinking,omitempty"`
+	}{
+		Model:      c.Model,
+		System:     req.system(),
+		Tools:      c.Tools,
+		Messages:   c.Messages,
+		ToolChoice: c.ToolChoice,
+		Thinking:   c.Thinking,
+	})
+}
+
+// CountTokensResponse is the response from the Anthropic API for a count tokens request.
+type CountTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
@@ -0,0 +1,164 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	MediaTypePDF       MediaType = "application/pdf"
+	MediaTypeTextPlain MediaType = "text/plain"
+)
+
+// Types of document sources.
+const (
+	DocumentSourceTypeBase64  = "base64"
+	DocumentSourceTypeText    = "text"
+	DocumentSourceTypeContent = "content"
+)
+
+// DocumentSource is the content of a document: a base64 encoded PDF, plain text, or custom content
+// made of text blocks, each citable on its own.
+type DocumentSource struct {
+	Type      string         `json:"type"`
+	MediaType MediaType      `json:"media_type,omitempty"`
+	Data      string         `json:"data,omitempty"`
+	Content   []ContentBlock `json:"content,omitempty"`
+}
+
+// UnmarshalJSON decodes the content of custom content sources into typed content blocks.
+func (s *DocumentSource) UnmarshalJSON(data []byte) error {
+	type alias DocumentSource
+	raw := struct {
+		*alias
+		Content json.RawMessage `json:"content"`
+	}{
+		alias: (*alias)(s),
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	s.Content, err = unmarshalContent(raw.Content)
+	return err
+}
+
+// CitationsConfig enables citations of a document in the response.
+type CitationsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// DocumentContentBlock represents a document, such as a PDF, the model can read and cite.
+type DocumentContentBlock struct {
+	Type         string           `json:"type"`
+	Source       DocumentSource   `json:"source"`
+	Title        string           `json:"title,omitempty"`     // optional
+	Context      string           `json:"context,omitempty"`   // optional
+	Citations    *CitationsConfig `json:"citations,omitempty"` // optional
+	CacheControl *CacheControl    `json:"cache_control,omitempty"`
+}
+
+func (d DocumentContentBlock) isContentBlock() {}
+
+// WithTitle returns a copy of the document with its title set.
+func (d DocumentContentBlock) WithTitle(title string) DocumentContentBlock {
+	d.Title = title
+	return d
+}
+
+// WithContext returns a copy of the document with context about it that is not cited, such as
+// metadata.
+func (d DocumentContentBlock) WithContext(context string) DocumentContentBlock {
+	d.Context = context
+	return d
+}
+
+// WithCitations returns a copy of the document with citations enabled.
+func (d DocumentContentBlock) WithCitations() DocumentContentBlock {
+	d.Citations = &CitationsConfig{Enabled: true}
+	return d
+}
+
+// NewPDFDocumentContentBlock creates a document from a base64 encoded PDF.
+func NewPDFDocumentContentBlock(base64Data string) DocumentContentBlock {
+	return DocumentContentBlock{
+		Type: "document",
+		Source: DocumentSource{
+			Type:      DocumentSourceTypeBase64,
+			MediaType: MediaTypePDF,
+			Data:      base64Data,
+		},
+	}
+}
+
+// NewTextDocumentContentBlock creates a plain text document, cited by character ranges.
+func NewTextDocumentContentBlock(text string) DocumentContentBlock {
+	return DocumentContentBlock{
+		Type: "document",
+		Source: DocumentSource{
+			Type:      DocumentSourceTypeText,
+			MediaType: MediaTypeTextPlain,
+			Data:      text,
+		},
+	}
+}
+
+// NewCustomDocumentContentBlock creates a document from text blocks, cited by block ranges.
+func NewCustomDocumentContentBlock(blocks ...ContentBlock) DocumentContentBlock {
+	return DocumentContentBlock{
+		Type: "document",
+		Source: DocumentSource{
+			Type:    DocumentSourceTypeContent,
+			Content: blocks,
+		},
+	}
+}
+
+// NewDocumentContentBlockFromReader creates a document from the content of r, a PDF or plain text
+// depending on mediaType.
+func NewDocumentContentBlockFromReader(r io.Reader, mediaType MediaType) (DocumentContentBlock, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return DocumentContentBlock{}, fmt.Errorf("error reading document: %w", err)
+	}
+
+	switch mediaType {
+	case MediaTypePDF:
+		return NewPDFDocumentContentBlock(base64.StdEncoding.EncodeToString(data)), nil
+	case MediaTypeTextPlain:
+		return NewTextDocumentContentBlock(string(data)), nil
+	}
+
+	return DocumentContentBlock{}, fmt.Errorf("unsupported document media type %s", mediaType)
+}
+
+// NewDocumentContentBlockFromFile creates a document from the file at path, titled with the file
+// name. PDF files are recognized by their content, other text files are read as plain text.
+func NewDocumentContentBlockFromFile(path string) (DocumentContentBlock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DocumentContentBlock{}, fmt.Errorf("error reading document: %w", err)
+	}
+
+	// text is detected with its charset, e.g. text/plain; charset=utf-8
+	mediaType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if MediaType(mediaType) != MediaTypePDF && MediaType(mediaType) != MediaTypeTextPlain {
+		return DocumentContentBlock{}, fmt.Errorf("unsupported document media type %s of %s", mediaType, path)
+	}
+
+	document, err := NewDocumentContentBlockFromReader(bytes.NewReader(data), MediaType(mediaType))
+	if err != nil {
+		return DocumentContentBlock{}, err
+	}
+
+	return document.WithTitle(filepath.Base(path)), nil
+}
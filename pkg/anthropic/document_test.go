@@ -0,0 +1,193 @@
+package anthropic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocumentContentBlockJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		block    ContentBlock
+		expected string
+	}{
+		{
+			name:     "pdf",
+			block:    NewPDFDocumentContentBlock("JVBERi0=").WithTitle("Report").WithCitations(),
+			expected: `{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"JVBERi0="},"title":"Report","citations":{"enabled":true}}`,
+		},
+		{
+			name:     "text",
+			block:    NewTextDocumentContentBlock("The grass is green.").WithContext("Written in 2024"),
+			expected: `{"type":"document","source":{"type":"text","media_type":"text/plain","data":"The grass is green."},"context":"Written in 2024"}`,
+		},
+		{
+			name:     "custom content",
+			block:    NewCustomDocumentContentBlock(NewTextContentBlock("First chunk"), NewTextContentBlock("Second chunk")),
+			expected: `{"type":"document","source":{"type":"content","content":[{"type":"text","text":"First chunk"},{"type":"text","text":"Second chunk"}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.block)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(data) != tt.expected {
+				t.Errorf("got %s, want %s", data, tt.expected)
+			}
+
+			block, err := unmarshalContentBlock(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(block, tt.block) {
+				t.Errorf("expected the block to round-trip, got %+v", block)
+			}
+		})
+	}
+}
+
+func TestNewDocumentContentBlockFromFile(t *testing.T) {
+	dir := t.TempDir()
+
+	pdf := []byte("%PDF-1.4\n%fake pdf")
+	pdfPath := filepath.Join(dir, "report.pdf")
+	err := os.WriteFile(pdfPath, pdf, 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	document, err := NewDocumentContentBlockFromFile(pdfPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if document.Source.MediaType != MediaTypePDF || document.Source.Data != base64.StdEncoding.EncodeToString(pdf) || document.Title != "report.pdf" {
+		t.Errorf("unexpected pdf document %+v", document)
+	}
+
+	textPath := filepath.Join(dir, "notes.txt")
+	err = os.WriteFile(textPath, []byte("The grass is green."), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	document, err = NewDocumentContentBlockFromFile(textPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if document.Source.Type != DocumentSourceTypeText || document.Source.Data != "The grass is green." {
+		t.Errorf("unexpected text document %+v", document)
+	}
+
+	pngPath := filepath.Join(dir, "image.png")
+	err = os.WriteFile(pngPath, []byte("\x89PNG\r\n\x1a\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = NewDocumentContentBlockFromFile(pngPath)
+	if err == nil || !strings.Contains(err.Error(), "unsupported document media type image/png") {
+		t.Errorf("expected an unsupported media type error, got %v", err)
+	}
+}
+
+func TestNewDocumentContentBlockFromReader(t *testing.T) {
+	document, err := NewDocumentContentBlockFromReader(strings.NewReader("The sky is blue."), MediaTypeTextPlain)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if document.Source.Data != "The sky is blue." {
+		t.Errorf("unexpected document %+v", document)
+	}
+
+	_, err = NewDocumentContentBlockFromReader(strings.NewReader(""), MediaTypePNG)
+	if err == nil {
+		t.Error("expected an error for an unsupported media type")
+	}
+}
+
+func TestResponseCitations(t *testing.T) {
+	data := `{"id":"msg_01","type":"message","role":"assistant","content":[` +
+		`{"type":"text","text":"the grass is green","citations":[{"type":"char_location","cited_text":"The grass is green.","document_index":0,"document_title":"Example","start_char_index":0,"end_char_index":20}]},` +
+		`{"type":"text","text":" and the report agrees","citations":[{"type":"page_location","cited_text":"Grass: green","document_index":1,"document_title":null,"start_page_number":1,"end_page_number":2}]}` +
+		`]}`
+
+	response := &MessageResponse{}
+	err := json.Unmarshal([]byte(data), response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []ContentBlock{
+		TextContentBlock{Type: "text", Text: "the grass is green", Citations: []Citation{{
+			Type: CitationTypeCharLocation, CitedText: "The grass is green.", DocumentTitle: "Example", EndCharIndex: 20,
+		}}},
+		TextContentBlock{Type: "text", Text: " and the report agrees", Citations: []Citation{{
+			Type: CitationTypePageLocation, CitedText: "Grass: green", DocumentIndex: 1, StartPageNumber: 1, EndPageNumber: 2,
+		}}},
+	}
+	if !reflect.DeepEqual(response.Content, expected) {
+		t.Errorf("unexpected content, got: %+v, want: %+v", response.Content, expected)
+	}
+
+	// zero indexes are kept when the citation is sent back, fields of other locations are not sent
+	citation, err := json.Marshal(expected[0].(TextContentBlock).Citations[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedCitation := `{"cited_text":"The grass is green.","document_index":0,"document_title":"Example","end_char_index":20,"start_char_index":0,"type":"char_location"}`
+	if string(citation) != expectedCitation {
+		t.Errorf("got %s, want %s", citation, expectedCitation)
+	}
+}
+
+func TestMessageStreamAccumulatorCitations(t *testing.T) {
+	events := []struct {
+		eventType MessageEventType
+		event     string
+	}{
+		{MessageEventTypeMessageStart, `{"type": "message_start", "message": {"id": "msg_01", "type": "message", "role": "assistant", "content": [], "model": "claude-3-5-sonnet-20241022", "usage": {"input_tokens": 25, "output_tokens": 1}}}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": "", "citations": []}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "citations_delta", "citation": {"type": "content_block_location", "cited_text": "Second chunk", "document_index": 0, "document_title": null, "start_block_index": 1, "end_block_index": 2}}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "the second chunk"}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 0}`},
+		{MessageEventTypeMessageStop, `{"type": "message_stop"}`},
+	}
+
+	accumulator := NewMessageStreamAccumulator()
+	for _, test := range events {
+		event, err := ParseMessageEvent(test.eventType, test.event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err = accumulator.Add(event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []ContentBlock{
+		TextContentBlock{Type: "text", Text: "the second chunk", Citations: []Citation{{
+			Type: CitationTypeContentBlockLocation, CitedText: "Second chunk", StartBlockIndex: 1, EndBlockIndex: 2,
+		}}},
+	}
+
+	response := accumulator.Response()
+	if !reflect.DeepEqual(response.Content, expected) {
+		t.Errorf("unexpected content, got: %+v, want: %+v", response.Content, expected)
+	}
+}
@@ -1,20 +1,32 @@
 package anthropic
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 )
 
 var (
-	ErrAnthropicInvalidRequest = errors.New("invalid request: there was an issue with the format or content of your request")
-	ErrAnthropicUnauthorized   = errors.New("unauthorized: there's an issue with your API key")
-	ErrAnthropicForbidden      = errors.New("forbidden: your API key does not have permission to use the specified resource")
-	ErrAnthropicRateLimit      = errors.New("your account has hit a rate limit")
-	ErrAnthropicInternalServer = errors.New("an unexpected error has occurred internal to Anthropic's systems")
+	ErrAnthropicInvalidRequest  = errors.New("invalid request: there was an issue with the format or content of your request")
+	ErrAnthropicUnauthorized    = errors.New("unauthorized: there's an issue with your API key")
+	ErrAnthropicForbidden       = errors.New("forbidden: your API key does not have permission to use the specified resource")
+	ErrAnthropicNotFound        = errors.New("not found: the requested resource was not found")
+	ErrAnthropicRequestTooLarge = errors.New("request too large: the request exceeds the maximum allowed number of bytes")
+	ErrAnthropicRateLimit       = errors.New("your account has hit a rate limit")
+	ErrAnthropicInternalServer  = errors.New("an unexpected error has occurred internal to Anthropic's systems")
+	ErrAnthropicOverloaded      = errors.New("overloaded: Anthropic's API is temporarily overloaded")
+	ErrAnthropicUnknown         = errors.New("unknown error occurred")
 
 	ErrAnthropicApiKeyRequired = errors.New("apiKey is required")
+
+	ErrCountTokensUnsupported = errors.New("token counting is not supported by this client")
 )
 
+// StatusOverloaded is the non-standard HTTP status code returned when the API is overloaded.
+const StatusOverloaded = 529
+
 // mapHTTPStatusCodeToError maps an HTTP status code to an error.
 func MapHTTPStatusCodeToError(code int) error {
 	switch code {
@@ -24,11 +36,113 @@ func MapHTTPStatusCodeToError(code int) error {
 		return ErrAnthropicUnauthorized
 	case http.StatusForbidden:
 		return ErrAnthropicForbidden
+	case http.StatusNotFound:
+		return ErrAnthropicNotFound
+	case http.StatusRequestEntityTooLarge:
+		return ErrAnthropicRequestTooLarge
 	case http.StatusTooManyRequests:
 		return ErrAnthropicRateLimit
 	case http.StatusInternalServerError:
 		return ErrAnthropicInternalServer
+	case StatusOverloaded:
+		return ErrAnthropicOverloaded
 	default:
-		return errors.New("unknown error occurred")
+		return ErrAnthropicUnknown
+	}
+}
+
+// errorTypeSentinels maps the error types reported in API error bodies to their sentinel errors.
+var errorTypeSentinels = map[string]error{
+	"invalid_request_error": ErrAnthropicInvalidRequest,
+	"authentication_error":  ErrAnthropicUnauthorized,
+	"permission_error":      ErrAnthropicForbidden,
+	"not_found_error":       ErrAnthropicNotFound,
+	"request_too_large":     ErrAnthropicRequestTooLarge,
+	"rate_limit_error":      ErrAnthropicRateLimit,
+	"api_error":             ErrAnthropicInternalServer,
+	"overloaded_error":      ErrAnthropicOverloaded,
+}
+
+// APIError is returned when the API responds with an error. It matches the ErrAnthropic* sentinel
+// of its status code with errors.Is:
+//
+//	var apiErr *anthropic.APIError
+//	if errors.As(err, &apiErr) && errors.Is(err, anthropic.ErrAnthropicOverloaded) {
+//		log.Printf("overloaded, request-id: %s", apiErr.RequestID)
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status of the response, 0 when the error wasn't received over HTTP.
+	StatusCode int
+	// Type is the error type reported by the API, e.g. invalid_request_error or overloaded_error.
+	Type string
+	// Message is the error message reported by the API.
+	Message string
+	// RequestID identifies the failed request, useful when contacting support.
+	RequestID string
+	// Body is the raw response body, when available.
+	Body []byte
+}
+
+// NewAPIError creates an APIError from an error response of the API, extracting the error type and
+// message from its body.
+func NewAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Body:       body,
+	}
+
+	if header != nil {
+		apiErr.RequestID = header.Get("request-id")
+	}
+
+	errorBody := struct {
+		Error struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}{}
+	if json.Unmarshal(body, &errorBody) == nil {
+		apiErr.Type = errorBody.Error.Type
+		apiErr.Message = errorBody.Error.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	details := []string{}
+	if e.StatusCode != 0 {
+		details = append(details, fmt.Sprintf("status %d", e.StatusCode))
+	}
+	if e.Type != "" {
+		details = append(details, fmt.Sprintf("type %s", e.Type))
+	}
+	if e.RequestID != "" {
+		details = append(details, fmt.Sprintf("request-id %s", e.RequestID))
+	}
+
+	message := e.Unwrap().Error()
+	if e.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Message)
+	}
+	if len(details) == 0 {
+		return message
+	}
+	return fmt.Sprintf("%s (%s)", message, strings.Join(details, ", "))
+}
+
+// Unwrap returns the ErrAnthropic* sentinel matching the status code, falling back to the one
+// matching the error type for status codes without a sentinel.
+func (e *APIError) Unwrap() error {
+	sentinel := MapHTTPStatusCodeToError(e.StatusCode)
+	if sentinel != ErrAnthropicUnknown {
+		return sentinel
+	}
+
+	if typeSentinel, ok := errorTypeSentinels[e.Type]; ok {
+		return typeSentinel
 	}
+	return sentinel
 }
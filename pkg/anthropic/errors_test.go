@@ -16,7 +16,10 @@ func TestMapHTTPStatusCodeToError(t *testing.T) {
 		{http.StatusForbidden, ErrAnthropicForbidden},
 		{http.StatusTooManyRequests, ErrAnthropicRateLimit},
 		{http.StatusInternalServerError, ErrAnthropicInternalServer},
-		{http.StatusNotFound, errors.New("unknown error occurred")},
+		{http.StatusNotFound, ErrAnthropicNotFound},
+		{http.StatusRequestEntityTooLarge, ErrAnthropicRequestTooLarge},
+		{StatusOverloaded, ErrAnthropicOverloaded},
+		{http.StatusTeapot, errors.New("unknown error occurred")},
 	}
 
 	for _, test := range tests {
@@ -26,3 +29,54 @@ func TestMapHTTPStatusCodeToError(t *testing.T) {
 		}
 	}
 }
+
+func TestNewAPIError(t *testing.T) {
+	header := http.Header{}
+	header.Set("request-id", "req_123")
+	body := []byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+
+	err := NewAPIError(StatusOverloaded, header, body)
+
+	if err.StatusCode != StatusOverloaded || err.Type != "overloaded_error" || err.Message != "Overloaded" || err.RequestID != "req_123" {
+		t.Errorf("Unexpected APIError %+v", err)
+	}
+
+	if string(err.Body) != string(body) {
+		t.Errorf("Expected body %s, got %s", body, err.Body)
+	}
+
+	if !errors.Is(err, ErrAnthropicOverloaded) {
+		t.Errorf("Expected error to match ErrAnthropicOverloaded")
+	}
+
+	expected := "overloaded: Anthropic's API is temporarily overloaded: Overloaded (status 529, type overloaded_error, request-id req_123)"
+	if err.Error() != expected {
+		t.Errorf("Expected error '%s', got '%s'", expected, err.Error())
+	}
+}
+
+func TestAPIErrorUnwrap(t *testing.T) {
+	tests := []struct {
+		err      *APIError
+		expected error
+	}{
+		{&APIError{StatusCode: http.StatusBadRequest, Type: "invalid_request_error"}, ErrAnthropicInvalidRequest},
+		{&APIError{StatusCode: http.StatusServiceUnavailable, Type: "overloaded_error"}, ErrAnthropicOverloaded},
+		{&APIError{Type: "rate_limit_error"}, ErrAnthropicRateLimit},
+		{&APIError{StatusCode: http.StatusTeapot}, ErrAnthropicUnknown},
+	}
+
+	for _, test := range tests {
+		if !errors.Is(test.err, test.expected) {
+			t.Errorf("Expected %+v to match '%s'", test.err, test.expected)
+		}
+	}
+}
+
+func TestNewAPIErrorPlainBody(t *testing.T) {
+	err := NewAPIError(http.StatusBadGateway, nil, []byte("Bad Gateway\n"))
+
+	if err.Message != "Bad Gateway" || err.Type != "" {
+		t.Errorf("Unexpected APIError %+v", err)
+	}
+}
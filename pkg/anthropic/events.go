@@ -21,3 +21,12 @@ const (
 	CompletionEventTypeCompletion CompletionEventType = "completion"
 	CompletionEventTypePing       CompletionEventType = "ping"
 )
+
+const (
+	// Constants for content block delta types
+	ContentBlockDeltaTypeText      = "text_delta"
+	ContentBlockDeltaTypeInputJSON = "input_json_delta"
+	ContentBlockDeltaTypeThinking  = "thinking_delta"
+	ContentBlockDeltaTypeSignature = "signature_delta"
+	ContentBlockDeltaTypeCitations = "citations_delta"
+)
@@ -0,0 +1,286 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+const (
+	// sentinel is echoed after each command, followed by its exit code, to find the end of its output.
+	sentinel = "<<anthropic-go-exit>>"
+	// pipeCloseDelay bounds how long a stopped session waits for processes it started to release
+	// its output.
+	pipeCloseDelay = 500 * time.Millisecond
+)
+
+// Bash runs a bash tool call in the persistent session and returns its output, stdout and stderr
+// combined. The session is started on the first command and keeps its working directory and
+// environment between commands. A command exceeding the timeout stops the session along with the
+// processes it started, the next command starts a new one.
+func (e *Executor) Bash(ctx context.Context, input interface{}) (string, error) {
+	bashInput, err := anthropic.DecodeBashInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	e.bashMu.Lock()
+	defer e.bashMu.Unlock()
+
+	if bashInput.Restart {
+		if e.bash != nil {
+			e.bash.close()
+			e.bash = nil
+		}
+
+		e.bash, err = startBashSession(e.shell, e.workDir, e.maxOutputBytes)
+		if err != nil {
+			return "", err
+		}
+		return "tool has been restarted.", nil
+	}
+
+	if e.bash == nil {
+		e.bash, err = startBashSession(e.shell, e.workDir, e.maxOutputBytes)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	output, exitCode, err := e.bash.run(ctx, bashInput.Command, e.commandTimeout)
+	if err != nil {
+		// the state of the session is unknown once a command didn't complete
+		e.bash.close()
+		e.bash = nil
+		return "", err
+	}
+
+	if exitCode != 0 {
+		output += fmt.Sprintf("\n(exit code %d)", exitCode)
+	}
+
+	return output, nil
+}
+
+// bashSession is a shell process reading commands from its stdin.
+type bashSession struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	output *outputBuffer
+	exited chan struct{}
+}
+
+// startBashSession starts a shell in dir, keeping at most limit bytes of the output of each
+// command.
+func startBashSession(shell, dir string, limit int) (*bashSession, error) {
+	output := newOutputBuffer(limit)
+
+	cmd := exec.Command(shell)
+	cmd.Dir = dir
+	// using the same writer for both sends stdout and stderr through a single pipe, in order
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = pipeCloseDelay
+	startProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error starting bash session: %w", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("error starting bash session: %w", err)
+	}
+
+	session := &bashSession{
+		cmd:    cmd,
+		stdin:  stdin,
+		output: output,
+		exited: make(chan struct{}),
+	}
+
+	go func() {
+		cmd.Wait()
+		close(session.exited)
+	}()
+
+	return session, nil
+}
+
+// run sends command to the shell and waits for its output and exit code. The command reads its
+// input from /dev/null, so it can't consume the rest of the script sent to the shell.
+func (s *bashSession) run(ctx context.Context, command string, timeout time.Duration) (string, int, error) {
+	s.output.reset()
+
+	_, err := io.WriteString(s.stdin, "{ "+command+"\n} < /dev/null\necho \""+sentinel+"$?\"\n")
+	if err != nil {
+		return "", 0, fmt.Errorf("error sending command to bash: %w", err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		output, exitCode, ok := s.output.result()
+		if ok {
+			return output, exitCode, nil
+		}
+
+		select {
+		case <-s.output.notify:
+		case <-s.exited:
+			return "", 0, fmt.Errorf("bash session exited: %s", strings.TrimSpace(s.output.String()))
+		case <-timer.C:
+			return "", 0, fmt.Errorf("bash command timed out after %s, the session was stopped and the next command starts a new one", timeout)
+		case <-ctx.Done():
+			return "", 0, ctx.Err()
+		}
+	}
+}
+
+// close stops the shell and the processes it started, including those left in the background.
+func (s *bashSession) close() error {
+	s.stdin.Close()
+
+	err := killProcessGroup(s.cmd)
+	<-s.exited
+	if err != nil {
+		return fmt.Errorf("error stopping bash session: %w", err)
+	}
+	return nil
+}
+
+// outputBuffer collects the output of the shell, signalling every write. It keeps at most limit
+// bytes of the output of a command, and only scans the bytes written since the last write for the
+// sentinel.
+type outputBuffer struct {
+	mu    sync.Mutex
+	limit int
+	// output is the start of the output of the command, dropped counts the bytes left out of it.
+	output  bytes.Buffer
+	dropped int
+	// window holds the bytes which may be the start of the sentinel line.
+	window []byte
+	// done is set once the sentinel line was received, with the exit code it carried.
+	done     bool
+	exitCode int
+	notify   chan struct{}
+}
+
+func newOutputBuffer(limit int) *outputBuffer {
+	return &outputBuffer{limit: limit, notify: make(chan struct{}, 1)}
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	if !b.done {
+		b.scan(p)
+	}
+	b.mu.Unlock()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+
+	return len(p), nil
+}
+
+// scan adds p to the output, looking for the sentinel line in the window and the new bytes.
+func (b *outputBuffer) scan(p []byte) {
+	b.window = append(b.window, p...)
+
+	index := bytes.Index(b.window, []byte(sentinel))
+	if index < 0 {
+		// all but the bytes that could start the sentinel are output
+		keep := max(len(b.window)-(len(sentinel)-1), 0)
+		b.keep(b.window[:keep])
+		b.window = append(b.window[:0], b.window[keep:]...)
+		return
+	}
+
+	b.keep(b.window[:index])
+	b.window = append(b.window[:0], b.window[index:]...)
+
+	status, _, complete := bytes.Cut(b.window[len(sentinel):], []byte("\n"))
+	if !complete {
+		return
+	}
+
+	exitCode, err := strconv.Atoi(string(status))
+	if err != nil {
+		exitCode = -1
+	}
+
+	b.done = true
+	b.exitCode = exitCode
+	b.window = nil
+}
+
+// keep appends p to the output, up to the limit. A few bytes more than the limit are kept so the
+// output can be cut without splitting a UTF-8 character.
+func (b *outputBuffer) keep(p []byte) {
+	if b.limit < 0 {
+		b.output.Write(p)
+		return
+	}
+
+	room := max(b.limit+utf8.UTFMax-b.output.Len(), 0)
+	if len(p) > room {
+		b.dropped += len(p) - room
+		p = p[:room]
+	}
+	b.output.Write(p)
+}
+
+func (b *outputBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.output.String() + string(b.window)
+}
+
+func (b *outputBuffer) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.output.Reset()
+	b.dropped = 0
+	b.window = nil
+	b.done = false
+	b.exitCode = 0
+}
+
+// result returns the output of the command and its exit code once the sentinel line was received.
+// Output over the limit is cut the same way as utils.Truncate.
+func (b *outputBuffer) result() (string, int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.done {
+		return "", 0, false
+	}
+
+	output := b.output.String()
+	if b.dropped == 0 {
+		output = strings.TrimSuffix(output, "\n")
+		if b.limit < 0 || len(output) <= b.limit {
+			return output, b.exitCode, true
+		}
+	}
+
+	cut := b.limit
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s... (%d bytes truncated)", output[:cut], len(output)-cut+b.dropped), b.exitCode, true
+}
@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func bashCommand(t *testing.T, e *Executor, command string) string {
+	output, err := e.Bash(context.Background(), map[string]interface{}{"command": command})
+	if err != nil {
+		t.Fatalf("unexpected error running %q: %v", command, err)
+	}
+	return output
+}
+
+func TestBashSession(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+
+	if output := bashCommand(t, e, "pwd"); output != e.workDir {
+		t.Errorf("expected the session to start in %s, got %q", e.workDir, output)
+	}
+
+	// the session keeps its state between commands
+	bashCommand(t, e, "export GREETING=hello && mkdir sub && cd sub")
+	if output := bashCommand(t, e, `echo "$GREETING from $(basename "$PWD")"`); output != "hello from sub" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	if output := bashCommand(t, e, "echo out; echo err >&2; false"); output != "out\nerr\n(exit code 1)" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	output, err := e.Bash(context.Background(), map[string]interface{}{"restart": true})
+	if err != nil || output != "tool has been restarted." {
+		t.Fatalf("unexpected restart result %q, %v", output, err)
+	}
+
+	if output := bashCommand(t, e, `echo "[$GREETING]"`); output != "[]" {
+		t.Errorf("expected a fresh session, got %q", output)
+	}
+}
+
+func TestBashTimeout(t *testing.T) {
+	e := makeTestExecutor(t, Config{CommandTimeout: 100 * time.Millisecond})
+
+	bashCommand(t, e, "export GREETING=hello")
+
+	_, err := e.Bash(context.Background(), map[string]interface{}{"command": "sleep 5"})
+	if err == nil || !strings.HasPrefix(err.Error(), "bash command timed out after 100ms") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	if output := bashCommand(t, e, `echo "[$GREETING]"`); output != "[]" {
+		t.Errorf("expected a new session after the timeout, got %q", output)
+	}
+}
+
+func TestBashStdin(t *testing.T) {
+	e := makeTestExecutor(t, Config{CommandTimeout: 5 * time.Second})
+
+	bashCommand(t, e, "export GREETING=hello")
+
+	// commands reading stdin get an empty input instead of the rest of the script
+	if output := bashCommand(t, e, "cat"); output != "" {
+		t.Errorf("expected no output, got %q", output)
+	}
+	if output := bashCommand(t, e, `read x; echo "[$x]"`); output != "[]" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	if output := bashCommand(t, e, `echo "$GREETING"`); output != "hello" {
+		t.Errorf("expected the session to be kept, got %q", output)
+	}
+}
+
+func TestBashOutputTruncation(t *testing.T) {
+	e := makeTestExecutor(t, Config{MaxOutputBytes: 10})
+
+	output := bashCommand(t, e, "printf '%.0sa' $(seq 1 100)")
+	if output != "aaaaaaaaaa... (90 bytes truncated)" {
+		t.Errorf("unexpected output %q", output)
+	}
+}
+
+func TestBashSessionExit(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+
+	_, err := e.Bash(context.Background(), map[string]interface{}{"command": "echo bye; exit 3"})
+	if err == nil || err.Error() != "bash session exited: bye" {
+		t.Fatalf("expected the session to exit, got %v", err)
+	}
+
+	if output := bashCommand(t, e, "echo back"); output != "back" {
+		t.Errorf("expected a new session, got %q", output)
+	}
+}
+
+func TestBashOutputLimit(t *testing.T) {
+	e := makeTestExecutor(t, Config{MaxOutputBytes: 4})
+
+	output := bashCommand(t, e, "yes | head -c 10000000")
+	if output != "y\ny\n... (9999996 bytes truncated)" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	if e.bash.output.output.Len() > 4+utf8.UTFMax {
+		t.Errorf("expected the retained output to be capped, got %d bytes", e.bash.output.output.Len())
+	}
+}
+
+func TestOutputBufferSplitSentinel(t *testing.T) {
+	buffer := newOutputBuffer(-1)
+
+	// the sentinel line is split across writes
+	for _, chunk := range []string{"hello\n<<anthropic", "-go-exit>>", "4", "2\n"} {
+		if _, _, ok := buffer.result(); ok {
+			t.Fatalf("unexpected result before %q", chunk)
+		}
+		buffer.Write([]byte(chunk))
+	}
+
+	output, exitCode, ok := buffer.result()
+	if !ok || output != "hello" || exitCode != 42 {
+		t.Errorf("unexpected result %q, %d, %v", output, exitCode, ok)
+	}
+}
@@ -0,0 +1,326 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/utils"
+)
+
+// snippetContext is the number of lines shown around an edit.
+const snippetContext = 4
+
+// TextEditor runs a str_replace_editor tool call and returns its output.
+func (e *Executor) TextEditor(input interface{}) (string, error) {
+	editorInput, err := anthropic.DecodeTextEditorInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := e.resolvePath(editorInput.Path)
+	if err != nil {
+		return "", err
+	}
+
+	e.editorMu.Lock()
+	defer e.editorMu.Unlock()
+
+	var output string
+	switch editorInput.Command {
+	case anthropic.TextEditorCommandView:
+		output, err = e.view(path, editorInput.ViewRange)
+	case anthropic.TextEditorCommandCreate:
+		output, err = e.create(path, editorInput.FileText)
+	case anthropic.TextEditorCommandStrReplace:
+		output, err = e.strReplace(path, editorInput.OldStr, editorInput.NewStr)
+	case anthropic.TextEditorCommandInsert:
+		output, err = e.insert(path, *editorInput.InsertLine, editorInput.NewStr)
+	case anthropic.TextEditorCommandUndoEdit:
+		output, err = e.undoEdit(path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return utils.Truncate(output, e.maxOutputBytes), nil
+}
+
+// resolvePath returns the absolute path of path, relative paths being relative to the working
+// directory. Paths leading outside of the working directory, including through symbolic links, are
+// rejected.
+func (e *Executor) resolvePath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(e.workDir, path)
+	}
+	path = filepath.Clean(path)
+
+	// resolve the links of the part of the path that exists, the rest is created by the editor
+	existing, missing := path, ""
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			path = filepath.Join(resolved, missing)
+			break
+		}
+
+		// a dangling link can't be resolved, writing through it would create its target
+		info, lstatErr := os.Lstat(existing)
+		if lstatErr == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("path %s goes through the dangling symbolic link %s", path, existing)
+		}
+		if !errors.Is(lstatErr, fs.ErrNotExist) {
+			return "", fmt.Errorf("error resolving path %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", fmt.Errorf("error resolving path %s: %w", path, err)
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = parent
+	}
+
+	rel, err := filepath.Rel(e.workDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside of the working directory %s", path, e.workDir)
+	}
+
+	return path, nil
+}
+
+// readFile returns the content of the file at path, rejecting directories.
+func readFile(path string) (string, fs.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading file: %w", err)
+	}
+	if info.IsDir() {
+		return "", 0, fmt.Errorf("%s is a directory, only the view command can be used on directories", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return string(data), info.Mode().Perm(), nil
+}
+
+// writeEdit replaces the content of the file at path, recording its previous content for undo_edit.
+func (e *Executor) writeEdit(path, previous, content string, mode fs.FileMode) error {
+	err := os.WriteFile(path, []byte(content), mode)
+	if err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	e.history[path] = append(e.history[path], previous)
+	return nil
+}
+
+func (e *Executor) view(path string, viewRange []int) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+
+	if info.IsDir() {
+		if viewRange != nil {
+			return "", fmt.Errorf("view_range can't be used when viewing a directory")
+		}
+		return viewDir(path)
+	}
+
+	content, _, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	lines := splitLines(content)
+	start, end := 1, len(lines)
+	if viewRange != nil {
+		start, end = viewRange[0], viewRange[1]
+		if end == -1 {
+			end = len(lines)
+		}
+
+		if start < 1 || start > max(len(lines), 1) || end < start || end > len(lines) {
+			return "", fmt.Errorf("view_range %v is out of range, %s has %d lines", viewRange, path, len(lines))
+		}
+	}
+
+	return fmt.Sprintf("Here's the result of running `cat -n` on %s:\n%s", path, numberLines(lines, start, end)), nil
+}
+
+// viewDir lists the files and directories up to 2 levels deep in path, leaving out hidden ones.
+func viewDir(path string) (string, error) {
+	entries := []string{}
+	err := filepath.WalkDir(path, func(entry string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry != path && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		entries = append(entries, entry)
+
+		rel, _ := filepath.Rel(path, entry)
+		if d.IsDir() && entry != path && strings.Count(rel, string(filepath.Separator)) >= 1 {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error listing directory: %w", err)
+	}
+
+	return fmt.Sprintf("Here's the files and directories up to 2 levels deep in %s, excluding hidden items:\n%s\n", path, strings.Join(entries, "\n")), nil
+}
+
+func (e *Executor) create(path, fileText string) (string, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return "", fmt.Errorf("error creating file: %w", err)
+	}
+
+	// O_EXCL also refuses to follow a link created since the path was resolved
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return "", fmt.Errorf("file already exists at %s, the create command can't overwrite files", path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error creating file: %w", err)
+	}
+
+	_, err = file.WriteString(fileText)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("error creating file: %w", err)
+	}
+
+	return fmt.Sprintf("File created successfully at: %s", path), nil
+}
+
+func (e *Executor) strReplace(path, oldStr, newStr string) (string, error) {
+	content, mode, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	switch count := strings.Count(content, oldStr); {
+	case count == 0:
+		return "", fmt.Errorf("no replacement was performed, old_str %q did not appear verbatim in %s", oldStr, path)
+	case count > 1:
+		return "", fmt.Errorf("no replacement was performed, old_str %q appears %d times in %s at lines %v, make it unique", oldStr, count, path, occurrenceLines(content, oldStr))
+	}
+
+	index := strings.Index(content, oldStr)
+	edited := content[:index] + newStr + content[index+len(oldStr):]
+	err = e.writeEdit(path, content, edited, mode)
+	if err != nil {
+		return "", err
+	}
+
+	startLine := strings.Count(content[:index], "\n") + 1
+	endLine := startLine + strings.Count(newStr, "\n")
+	return editedSnippet(path, edited, startLine, endLine), nil
+}
+
+func (e *Executor) insert(path string, insertLine int, newStr string) (string, error) {
+	content, mode, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	lines := splitLines(content)
+	if insertLine < 0 || insertLine > len(lines) {
+		return "", fmt.Errorf("insert_line %d is out of range [0, %d] of %s", insertLine, len(lines), path)
+	}
+
+	inserted := splitLines(newStr)
+	editedLines := append(append(append([]string{}, lines[:insertLine]...), inserted...), lines[insertLine:]...)
+
+	edited := strings.Join(editedLines, "\n")
+	if content == "" || strings.HasSuffix(content, "\n") {
+		edited += "\n"
+	}
+
+	err = e.writeEdit(path, content, edited, mode)
+	if err != nil {
+		return "", err
+	}
+
+	return editedSnippet(path, edited, insertLine+1, insertLine+len(inserted)), nil
+}
+
+func (e *Executor) undoEdit(path string) (string, error) {
+	history := e.history[path]
+	if len(history) == 0 {
+		return "", fmt.Errorf("no edit history found for %s", path)
+	}
+
+	_, mode, err := readFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(path, []byte(history[len(history)-1]), mode)
+	if err != nil {
+		return "", fmt.Errorf("error writing file: %w", err)
+	}
+	e.history[path] = history[:len(history)-1]
+
+	return fmt.Sprintf("Last edit to %s undone successfully.", path), nil
+}
+
+// splitLines splits content into lines, a final newline doesn't start a new line.
+func splitLines(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(content, "\n")
+}
+
+// numberLines formats lines start to end, 1-indexed, the way `cat -n` does.
+func numberLines(lines []string, start, end int) string {
+	builder := strings.Builder{}
+	for i := start; i <= end && i <= len(lines); i++ {
+		fmt.Fprintf(&builder, "%6d\t%s\n", i, lines[i-1])
+	}
+	return builder.String()
+}
+
+// editedSnippet shows the lines startLine to endLine of an edited file with some context around.
+func editedSnippet(path, content string, startLine, endLine int) string {
+	lines := splitLines(content)
+	start := max(startLine-snippetContext, 1)
+	end := min(endLine+snippetContext, len(lines))
+
+	return fmt.Sprintf("The file %s has been edited. Here's the result of running `cat -n` on a snippet of %s:\n%s", path, path, numberLines(lines, start, end))
+}
+
+// occurrenceLines returns the lines, 1-indexed, on which each occurrence of substr starts.
+func occurrenceLines(content, substr string) []int {
+	lines := []int{}
+	offset := 0
+	for {
+		index := strings.Index(content[offset:], substr)
+		if index < 0 {
+			return lines
+		}
+		lines = append(lines, strings.Count(content[:offset+index], "\n")+1)
+		offset += index + max(len(substr), 1)
+	}
+}
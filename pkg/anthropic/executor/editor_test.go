@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeTestExecutor(t *testing.T, cfg Config) *Executor {
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = t.TempDir()
+	}
+
+	e, err := MakeExecutor(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+
+	return e
+}
+
+func editorCommand(t *testing.T, e *Executor, input map[string]interface{}) string {
+	output, err := e.TextEditor(input)
+	if err != nil {
+		t.Fatalf("unexpected error running %v: %v", input, err)
+	}
+	return output
+}
+
+func readTestFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(data)
+}
+
+func TestTextEditorEdits(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+	path := filepath.Join(e.workDir, "src", "main.go")
+
+	output := editorCommand(t, e, map[string]interface{}{"command": "create", "path": path, "file_text": "package main\n\nfunc main() {\n}\n"})
+	if output != "File created successfully at: "+path {
+		t.Errorf("unexpected create output %q", output)
+	}
+
+	output = editorCommand(t, e, map[string]interface{}{"command": "str_replace", "path": "src/main.go", "old_str": "func main() {\n", "new_str": "func main() {\n\tprintln(\"hello\")\n"})
+	if !strings.Contains(output, "     4\t\tprintln(\"hello\")\n") {
+		t.Errorf("expected the snippet to show the edit, got %q", output)
+	}
+
+	editorCommand(t, e, map[string]interface{}{"command": "insert", "path": path, "insert_line": 0, "new_str": "// Command main greets."})
+	expected := "// Command main greets.\npackage main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n"
+	if content := readTestFile(t, path); content != expected {
+		t.Errorf("got %q, want %q", content, expected)
+	}
+
+	editorCommand(t, e, map[string]interface{}{"command": "undo_edit", "path": path})
+	editorCommand(t, e, map[string]interface{}{"command": "undo_edit", "path": path})
+	if content := readTestFile(t, path); content != "package main\n\nfunc main() {\n}\n" {
+		t.Errorf("expected both edits to be undone, got %q", content)
+	}
+
+	_, err := e.TextEditor(map[string]interface{}{"command": "undo_edit", "path": path})
+	if err == nil || err.Error() != "no edit history found for "+path {
+		t.Errorf("expected no edit history, got %v", err)
+	}
+}
+
+func TestTextEditorView(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+	path := filepath.Join(e.workDir, "notes.txt")
+	err := os.WriteFile(path, []byte("one\ntwo\nthree\nfour\n"), 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.MkdirAll(filepath.Join(e.workDir, "a", "b", "c"), 0o755)
+	os.MkdirAll(filepath.Join(e.workDir, ".git"), 0o755)
+
+	tests := []struct {
+		name     string
+		input    map[string]interface{}
+		expected string
+	}{
+		{
+			"file",
+			map[string]interface{}{"command": "view", "path": path},
+			"Here's the result of running `cat -n` on " + path + ":\n     1\tone\n     2\ttwo\n     3\tthree\n     4\tfour\n",
+		},
+		{
+			"range",
+			map[string]interface{}{"command": "view", "path": path, "view_range": []interface{}{2, -1}},
+			"Here's the result of running `cat -n` on " + path + ":\n     2\ttwo\n     3\tthree\n     4\tfour\n",
+		},
+		{
+			"directory",
+			map[string]interface{}{"command": "view", "path": e.workDir},
+			"Here's the files and directories up to 2 levels deep in " + e.workDir + ", excluding hidden items:\n" +
+				strings.Join([]string{e.workDir, filepath.Join(e.workDir, "a"), filepath.Join(e.workDir, "a", "b"), path}, "\n") + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := editorCommand(t, e, tt.input)
+			if output != tt.expected {
+				t.Errorf("got %q, want %q", output, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTextEditorErrors(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+	path := filepath.Join(e.workDir, "config.yaml")
+	err := os.WriteFile(path, []byte("a: 1\nb: 1\n"), 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	outside := t.TempDir()
+	err = os.Symlink(outside, filepath.Join(e.workDir, "link"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dangling := filepath.Join(outside, "dangling.txt")
+	err = os.Symlink(dangling, filepath.Join(e.workDir, "dangling"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		input  map[string]interface{}
+		expErr string
+	}{
+		{"not unique", map[string]interface{}{"command": "str_replace", "path": path, "old_str": ": 1", "new_str": ": 2"}, `no replacement was performed, old_str ": 1" appears 2 times in ` + path + " at lines [1 2], make it unique"},
+		{"missing", map[string]interface{}{"command": "str_replace", "path": path, "old_str": "c: 1", "new_str": ""}, `no replacement was performed, old_str "c: 1" did not appear verbatim in ` + path},
+		{"existing file", map[string]interface{}{"command": "create", "path": path, "file_text": ""}, "file already exists at " + path + ", the create command can't overwrite files"},
+		{"insert out of range", map[string]interface{}{"command": "insert", "path": path, "insert_line": 3, "new_str": "c: 1"}, "insert_line 3 is out of range [0, 2] of " + path},
+		{"view out of range", map[string]interface{}{"command": "view", "path": path, "view_range": []interface{}{2, 5}}, "view_range [2 5] is out of range, " + path + " has 2 lines"},
+		{"outside", map[string]interface{}{"command": "view", "path": "../etc/passwd"}, "path " + filepath.Join(filepath.Dir(e.workDir), "etc", "passwd") + " is outside of the working directory " + e.workDir},
+		{"symlink", map[string]interface{}{"command": "create", "path": "link/escape.txt", "file_text": ""}, "path " + filepath.Join(outside, "escape.txt") + " is outside of the working directory " + e.workDir},
+		{"dangling symlink", map[string]interface{}{"command": "create", "path": "dangling", "file_text": ""}, "path " + filepath.Join(e.workDir, "dangling") + " goes through the dangling symbolic link " + filepath.Join(e.workDir, "dangling")},
+		{"dangling symlink parent", map[string]interface{}{"command": "create", "path": "dangling/escape.txt", "file_text": ""}, "path " + filepath.Join(e.workDir, "dangling", "escape.txt") + " goes through the dangling symbolic link " + filepath.Join(e.workDir, "dangling")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.TextEditor(tt.input)
+			if err == nil || err.Error() != tt.expErr {
+				t.Errorf("expected error %s, got %v", tt.expErr, err)
+			}
+		})
+	}
+
+	if _, err := os.Lstat(dangling); err == nil {
+		t.Errorf("expected no file to be created outside of the working directory")
+	}
+
+	if content := readTestFile(t, path); content != "a: 1\nb: 1\n" {
+		t.Errorf("expected the file to be unchanged, got %q", content)
+	}
+}
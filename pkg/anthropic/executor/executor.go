@@ -0,0 +1,154 @@
+// Package executor runs the Anthropic-defined str_replace_editor and bash tools locally, so the
+// tool_use blocks of the model can be answered without reimplementing them:
+//
+//	e, err := executor.MakeExecutor(executor.Config{WorkDir: dir})
+//	defer e.Close()
+//	result := e.Execute(ctx, toolUse)
+//
+// The editor is confined to the working directory. Bash commands run in the working directory as
+// the current user and are not isolated, run the executor inside a container or VM to sandbox them.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/agent"
+)
+
+const (
+	// DefaultCommandTimeout is how long a bash command may run when no timeout is configured.
+	DefaultCommandTimeout = 2 * time.Minute
+	// DefaultMaxOutputBytes is how much of a tool's output is returned when no limit is configured.
+	DefaultMaxOutputBytes = 16 * 1024
+	// DefaultShell is the shell of the bash session when none is configured.
+	DefaultShell = "/bin/bash"
+)
+
+var ErrWorkDirRequired = errors.New("working directory is required")
+
+type Config struct {
+	// WorkDir is the directory the editor is confined to and bash commands start in.
+	WorkDir string
+	// Optional (defaults to DefaultCommandTimeout), how long a bash command may run before the
+	// session is killed.
+	CommandTimeout time.Duration
+	// Optional (defaults to DefaultMaxOutputBytes), the number of bytes of output returned to the
+	// model, -1 to disable truncation.
+	MaxOutputBytes int
+	// Optional (defaults to DefaultShell)
+	Shell string
+}
+
+// Executor executes str_replace_editor and bash tool calls. It is safe for concurrent use, bash
+// commands run one at a time in a single persistent session.
+type Executor struct {
+	workDir        string
+	commandTimeout time.Duration
+	maxOutputBytes int
+	shell          string
+
+	editorMu sync.Mutex
+	// history holds the previous contents of each edited file, for undo_edit.
+	history map[string][]string
+
+	bashMu sync.Mutex
+	bash   *bashSession
+}
+
+func MakeExecutor(cfg Config) (*Executor, error) {
+	if cfg.WorkDir == "" {
+		return nil, ErrWorkDirRequired
+	}
+
+	workDir, err := filepath.Abs(cfg.WorkDir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving working directory: %w", err)
+	}
+
+	workDir, err = filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving working directory: %w", err)
+	}
+
+	info, err := os.Stat(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("error opening working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("working directory %s is not a directory", workDir)
+	}
+
+	if cfg.CommandTimeout <= 0 {
+		cfg.CommandTimeout = DefaultCommandTimeout
+	}
+
+	if cfg.MaxOutputBytes == 0 {
+		cfg.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+
+	if cfg.Shell == "" {
+		cfg.Shell = DefaultShell
+	}
+
+	return &Executor{
+		workDir:        workDir,
+		commandTimeout: cfg.CommandTimeout,
+		maxOutputBytes: cfg.MaxOutputBytes,
+		shell:          cfg.Shell,
+		history:        map[string][]string{},
+	}, nil
+}
+
+// Execute runs the tool call and returns its tool_result block. Failures are reported to the model
+// with is_error set, as are calls to tools other than str_replace_editor and bash.
+func (e *Executor) Execute(ctx context.Context, toolUse anthropic.ToolUseContentBlock) anthropic.ContentBlock {
+	var output string
+	var err error
+
+	switch toolUse.Name {
+	case anthropic.ToolNameTextEditor:
+		output, err = e.TextEditor(toolUse.Input)
+	case anthropic.ToolNameBash:
+		output, err = e.Bash(ctx, toolUse.Input)
+	default:
+		err = fmt.Errorf("unknown tool: %s", toolUse.Name)
+	}
+
+	if err != nil {
+		return anthropic.NewToolResultContentBlock(toolUse.ID, err.Error(), true)
+	}
+	return anthropic.NewToolResultContentBlock(toolUse.ID, output, false)
+}
+
+// Handlers returns the agent.Runner handlers of the str_replace_editor and bash tools.
+func (e *Executor) Handlers() map[string]agent.ToolHandler {
+	return map[string]agent.ToolHandler{
+		anthropic.ToolNameTextEditor: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+			return e.TextEditor(input)
+		},
+		anthropic.ToolNameBash: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+			return e.Bash(ctx, input)
+		},
+	}
+}
+
+// Close stops the bash session, if one is running.
+func (e *Executor) Close() error {
+	e.bashMu.Lock()
+	defer e.bashMu.Unlock()
+
+	if e.bash == nil {
+		return nil
+	}
+
+	err := e.bash.close()
+	e.bash = nil
+	return err
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
+)
+
+func TestMakeExecutor(t *testing.T) {
+	_, err := MakeExecutor(Config{})
+	if err != ErrWorkDirRequired {
+		t.Errorf("expected %v, got %v", ErrWorkDirRequired, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(file, nil, 0o644)
+	_, err = MakeExecutor(Config{WorkDir: file})
+	if err == nil {
+		t.Error("expected an error for a file working directory")
+	}
+
+	e := makeTestExecutor(t, Config{})
+	if e.commandTimeout != DefaultCommandTimeout || e.maxOutputBytes != DefaultMaxOutputBytes || e.shell != DefaultShell {
+		t.Errorf("expected the defaults, got %+v", e)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		toolUse  anthropic.ToolUseContentBlock
+		expected anthropic.ContentBlock
+	}{
+		{
+			"bash",
+			anthropic.ToolUseContentBlock{ID: "toolu_1", Name: anthropic.ToolNameBash, Input: map[string]interface{}{"command": "echo hi"}},
+			anthropic.NewToolResultContentBlock("toolu_1", "hi", false),
+		},
+		{
+			"text editor",
+			anthropic.ToolUseContentBlock{ID: "toolu_2", Name: anthropic.ToolNameTextEditor, Input: map[string]interface{}{"command": "create", "path": "hi.txt", "file_text": "hi"}},
+			anthropic.NewToolResultContentBlock("toolu_2", "File created successfully at: "+filepath.Join(e.workDir, "hi.txt"), false),
+		},
+		{
+			"invalid input",
+			anthropic.ToolUseContentBlock{ID: "toolu_3", Name: anthropic.ToolNameTextEditor, Input: map[string]interface{}{"command": "delete", "path": "hi.txt"}},
+			anthropic.NewToolResultContentBlock("toolu_3", `unknown text editor command "delete"`, true),
+		},
+		{
+			"unknown tool",
+			anthropic.ToolUseContentBlock{ID: "toolu_4", Name: anthropic.ToolNameComputer, Input: map[string]interface{}{"action": "screenshot"}},
+			anthropic.NewToolResultContentBlock("toolu_4", "unknown tool: computer", true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := e.Execute(ctx, tt.toolUse)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("got %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	e := makeTestExecutor(t, Config{})
+	handlers := e.Handlers()
+
+	output, err := handlers[anthropic.ToolNameBash](context.Background(), map[string]interface{}{"command": "echo hi > hi.txt"})
+	if err != nil || output != "" {
+		t.Fatalf("unexpected bash result %q, %v", output, err)
+	}
+
+	output, err = handlers[anthropic.ToolNameTextEditor](context.Background(), map[string]interface{}{"command": "view", "path": "hi.txt"})
+	expected := "Here's the result of running `cat -n` on " + filepath.Join(e.workDir, "hi.txt") + ":\n     1\thi\n"
+	if err != nil || output != expected {
+		t.Errorf("got %q, %v, want %q", output, err, expected)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package executor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// startProcessGroup does nothing, process groups are only supported on unix.
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the process of cmd only, the commands it started keep running.
+func killProcessGroup(cmd *exec.Cmd) error {
+	err := cmd.Process.Kill()
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
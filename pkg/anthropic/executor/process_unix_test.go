@@ -0,0 +1,42 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBashTimeoutKillsProcessGroup(t *testing.T) {
+	e := makeTestExecutor(t, Config{CommandTimeout: 200 * time.Millisecond})
+
+	_, err := e.Bash(context.Background(), map[string]interface{}{"command": "sleep 1000 & echo $! > sleep.pid; wait"})
+	if err == nil || !strings.HasPrefix(err.Error(), "bash command timed out") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(e.workDir, "sleep.pid"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the killed process may take a moment to be reaped
+	deadline := time.Now().Add(2 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("expected the child process %d to be killed with the session", pid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,319 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the gif decoder used by image.Decode and image.DecodeConfig
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// MaxImageBytes is the maximum size of a single image accepted by the API.
+	MaxImageBytes = 5 * 1024 * 1024
+	// MaxImageDimension is the maximum width and height of an image accepted by the API.
+	MaxImageDimension = 8000
+	// RecommendedImageMaxEdge is the longest edge above which the API downscales images, adding
+	// latency without improving results.
+	RecommendedImageMaxEdge = 1568
+
+	// downscaleJPEGQuality is the quality of downscaled JPEG images.
+	downscaleJPEGQuality = 85
+	// maxDownscaleAttempts bounds how many times an image is shrunk to fit MaxImageBytes.
+	maxDownscaleAttempts = 5
+)
+
+// Types of image sources.
+const (
+	ImageSourceTypeBase64 = "base64"
+	ImageSourceTypeURL    = "url"
+)
+
+// NewImageURLContentBlock creates an image content block referencing an image by URL, fetched by
+// the API.
+func NewImageURLContentBlock(url string) ContentBlock {
+	return ImageContentBlock{
+		Type: "image",
+		Source: ImageSource{
+			Type: ImageSourceTypeURL,
+			URL:  url,
+		},
+	}
+}
+
+// ImageOption configures how the image constructors process an image.
+type ImageOption func(*imageOptions)
+
+type imageOptions struct {
+	maxEdge int
+}
+
+// WithImageDownscale downscales images whose longest edge exceeds maxEdge pixels, or
+// RecommendedImageMaxEdge when maxEdge is 0, keeping their aspect ratio. Images exceeding
+// MaxImageBytes are shrunk further until they fit. WEBP images can't be downscaled.
+func WithImageDownscale(maxEdge int) ImageOption {
+	return func(o *imageOptions) {
+		o.maxEdge = maxEdge
+		if o.maxEdge <= 0 {
+			o.maxEdge = RecommendedImageMaxEdge
+		}
+	}
+}
+
+// NewImageContentBlockFromBytes creates an image content block from encoded image data, detecting
+// its media type. Only JPEG, PNG, GIF and WEBP images are accepted.
+func NewImageContentBlockFromBytes(data []byte, options ...ImageOption) (ContentBlock, error) {
+	opts := imageOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	mediaType, err := DetectImageMediaType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.maxEdge > 0 {
+		data, mediaType, err = downscaleImage(data, mediaType, opts.maxEdge)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(data) > MaxImageBytes {
+		return nil, fmt.Errorf("image is %d bytes, maximum is %d", len(data), MaxImageBytes)
+	}
+
+	return NewImageContentBlock(mediaType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// NewImageContentBlockFromReader creates an image content block from the image read from r, see
+// NewImageContentBlockFromBytes.
+func NewImageContentBlockFromReader(r io.Reader, options ...ImageOption) (ContentBlock, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image: %w", err)
+	}
+
+	return NewImageContentBlockFromBytes(data, options...)
+}
+
+// NewImageContentBlockFromFile creates an image content block from the image file at path, see
+// NewImageContentBlockFromBytes.
+func NewImageContentBlockFromFile(path string, options ...ImageOption) (ContentBlock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading image: %w", err)
+	}
+
+	return NewImageContentBlockFromBytes(data, options...)
+}
+
+// DetectImageMediaType returns the media type of encoded image data, an error if it isn't one of
+// the image types supported by the API.
+func DetectImageMediaType(data []byte) (MediaType, error) {
+	mediaType := MediaType(http.DetectContentType(data))
+	switch mediaType {
+	case MediaTypeJPEG, MediaTypePNG, MediaTypeGIF, MediaTypeWEBP:
+		return mediaType, nil
+	}
+
+	return "", fmt.Errorf("unsupported image media type %s", mediaType)
+}
+
+// downscaleImage shrinks the image so its longest edge is at most maxEdge pixels and its size at
+// most MaxImageBytes. JPEG images are re-encoded as JPEG, PNG and GIF images as PNG.
+func downscaleImage(data []byte, mediaType MediaType, maxEdge int) ([]byte, MediaType, error) {
+	if mediaType == MediaTypeWEBP {
+		config, err := decodeWEBPConfig(data)
+		if err != nil || (config.Width <= maxEdge && config.Height <= maxEdge && len(data) <= MaxImageBytes) {
+			return data, mediaType, nil
+		}
+		return nil, "", fmt.Errorf("webp images can't be downscaled, convert the image to jpeg or png")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("error decoding image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() <= maxEdge && bounds.Dy() <= maxEdge && len(data) <= MaxImageBytes {
+		return data, mediaType, nil
+	}
+
+	if mediaType != MediaTypeJPEG {
+		mediaType = MediaTypePNG
+	}
+
+	for attempt := 0; attempt < maxDownscaleAttempts; attempt++ {
+		img = resizeImage(img, maxEdge)
+
+		buffer := &bytes.Buffer{}
+		if mediaType == MediaTypeJPEG {
+			err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: downscaleJPEGQuality})
+		} else {
+			err = png.Encode(buffer, img)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("error encoding downscaled image: %w", err)
+		}
+
+		if buffer.Len() <= MaxImageBytes {
+			return buffer.Bytes(), mediaType, nil
+		}
+
+		// still too large, shrink the longest edge by a quarter
+		maxEdge = max(img.Bounds().Dx(), img.Bounds().Dy()) * 3 / 4
+	}
+
+	return nil, "", fmt.Errorf("image exceeds %d bytes after downscaling", MaxImageBytes)
+}
+
+// resizeImage scales img down so its longest edge is at most maxEdge pixels, averaging the source
+// pixels covered by each destination pixel.
+func resizeImage(img image.Image, maxEdge int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxEdge && height <= maxEdge {
+		return img
+	}
+
+	newWidth, newHeight := maxEdge, height*maxEdge/width
+	if height > width {
+		newWidth, newHeight = width*maxEdge/height, maxEdge
+	}
+	newWidth, newHeight = max(newWidth, 1), max(newHeight, 1)
+
+	resized := image.NewNRGBA64(image.Rect(0, 0, newWidth, newHeight))
+	for y := 0; y < newHeight; y++ {
+		y0, y1 := bounds.Min.Y+y*height/newHeight, bounds.Min.Y+max((y+1)*height/newHeight, y*height/newHeight+1)
+		for x := 0; x < newWidth; x++ {
+			x0, x1 := bounds.Min.X+x*width/newWidth, bounds.Min.X+max((x+1)*width/newWidth, x*width/newWidth+1)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := color.NRGBA64Model.Convert(img.At(sx, sy)).(color.NRGBA64)
+					r += uint64(pixel.R)
+					g += uint64(pixel.G)
+					b += uint64(pixel.B)
+					a += uint64(pixel.A)
+					count++
+				}
+			}
+
+			resized.SetNRGBA64(x, y, color.NRGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return resized
+}
+
+// decodeWEBPConfig reads the dimensions of a WEBP image from its header, as the standard library
+// has no WEBP decoder.
+func decodeWEBPConfig(data []byte) (image.Config, error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return image.Config{}, fmt.Errorf("invalid webp header")
+	}
+
+	chunk := data[12:16]
+	switch string(chunk) {
+	case "VP8 ":
+		// lossy: 14 bit dimensions after the frame tag and start code
+		width := int(data[26]) | int(data[27]&0x3f)<<8
+		height := int(data[28]) | int(data[29]&0x3f)<<8
+		return image.Config{Width: width, Height: height}, nil
+	case "VP8L":
+		// lossless: 14 bit dimensions minus one after the signature byte
+		bits := uint32(data[21]) | uint32(data[22])<<8 | uint32(data[23])<<16 | uint32(data[24])<<24
+		return image.Config{Width: int(bits&0x3fff) + 1, Height: int(bits>>14&0x3fff) + 1}, nil
+	case "VP8X":
+		// extended: 24 bit dimensions minus one
+		width := int(data[24]) | int(data[25])<<8 | int(data[26])<<16
+		height := int(data[27]) | int(data[28])<<8 | int(data[29])<<16
+		return image.Config{Width: width + 1, Height: height + 1}, nil
+	}
+
+	return image.Config{}, fmt.Errorf("unknown webp chunk %q", chunk)
+}
+
+// decodeImageConfig reads the dimensions of a base64 encoded image without decoding all of it.
+func decodeImageConfig(mediaType MediaType, base64Data string) (image.Config, error) {
+	if mediaType == MediaTypeWEBP {
+		header := make([]byte, 30)
+		n, _ := io.ReadFull(base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Data)), header)
+		return decodeWEBPConfig(header[:n])
+	}
+
+	config, _, err := image.DecodeConfig(base64.NewDecoder(base64.StdEncoding, strings.NewReader(base64Data)))
+	return config, err
+}
+
+// validateImages checks the media type, size and dimensions of the base64 images of the request,
+// including the images returned in tool results. Images that can't be decoded are left for the API
+// to reject.
+func validateImages(req *MessageRequest) error {
+	for i, imageBlock := range requestImages(req) {
+		index := i + 1
+		if imageBlock.Source.Type != ImageSourceTypeBase64 {
+			continue
+		}
+
+		switch imageBlock.Source.MediaType {
+		case MediaTypeJPEG, MediaTypePNG, MediaTypeGIF, MediaTypeWEBP:
+		default:
+			return fmt.Errorf("image %d has unsupported media type %s", index, imageBlock.Source.MediaType)
+		}
+
+		size := base64.StdEncoding.DecodedLen(len(imageBlock.Source.Data)) - strings.Count(imageBlock.Source.Data, "=")
+		if size > MaxImageBytes {
+			return fmt.Errorf("image %d is %d bytes, maximum is %d", index, size, MaxImageBytes)
+		}
+
+		config, err := decodeImageConfig(imageBlock.Source.MediaType, imageBlock.Source.Data)
+		if err != nil {
+			continue
+		}
+
+		if config.Width > MaxImageDimension || config.Height > MaxImageDimension {
+			return fmt.Errorf("image %d is %dx%d pixels, maximum is %dx%d", index, config.Width, config.Height, MaxImageDimension, MaxImageDimension)
+		}
+	}
+
+	return nil
+}
+
+// requestImages returns the images of the messages of req in order, with the images of tool results
+// in place of their tool result.
+func requestImages(req *MessageRequest) []ImageContentBlock {
+	var images []ImageContentBlock
+	for _, message := range req.Messages {
+		for _, block := range message.Content {
+			switch block := block.(type) {
+			case ImageContentBlock:
+				images = append(images, block)
+			case ToolResultContentBlock:
+				content, _ := block.Content.([]ContentBlock)
+				for _, resultBlock := range content {
+					if imageBlock, ok := resultBlock.(ImageContentBlock); ok {
+						images = append(images, imageBlock)
+					}
+				}
+			}
+		}
+	}
+	return images
+}
@@ -0,0 +1,193 @@
+package anthropic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func encodeTestImage(t *testing.T, width, height int, mediaType MediaType) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	var err error
+	if mediaType == MediaTypeJPEG {
+		err = jpeg.Encode(buffer, img, nil)
+	} else {
+		err = png.Encode(buffer, img)
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func decodeImageBlock(t *testing.T, block ContentBlock) (ImageSource, image.Config) {
+	source := block.(ImageContentBlock).Source
+	config, err := decodeImageConfig(source.MediaType, source.Data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return source, config
+}
+
+func TestNewImageContentBlockFromBytes(t *testing.T) {
+	data := encodeTestImage(t, 40, 20, MediaTypePNG)
+
+	block, err := NewImageContentBlockFromBytes(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	source, config := decodeImageBlock(t, block)
+	if source.MediaType != MediaTypePNG || source.Data != base64.StdEncoding.EncodeToString(data) {
+		t.Errorf("expected the png to be sent unchanged, got %s", source.MediaType)
+	}
+	if config.Width != 40 || config.Height != 20 {
+		t.Errorf("unexpected dimensions %dx%d", config.Width, config.Height)
+	}
+
+	_, err = NewImageContentBlockFromBytes([]byte("%PDF-1.4"))
+	if err == nil || err.Error() != "unsupported image media type application/pdf" {
+		t.Errorf("expected an unsupported media type error, got %v", err)
+	}
+}
+
+func TestNewImageContentBlockDownscale(t *testing.T) {
+	tests := []struct {
+		name       string
+		mediaType  MediaType
+		width      int
+		height     int
+		maxEdge    int
+		wantWidth  int
+		wantHeight int
+	}{
+		{"landscape jpeg", MediaTypeJPEG, 200, 100, 50, 50, 25},
+		{"portrait png", MediaTypePNG, 60, 240, 120, 30, 120},
+		{"small image", MediaTypePNG, 20, 10, 0, 20, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodeTestImage(t, tt.width, tt.height, tt.mediaType)
+
+			block, err := NewImageContentBlockFromReader(bytes.NewReader(data), WithImageDownscale(tt.maxEdge))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			source, config := decodeImageBlock(t, block)
+			if source.MediaType != tt.mediaType {
+				t.Errorf("expected media type %s, got %s", tt.mediaType, source.MediaType)
+			}
+			if config.Width != tt.wantWidth || config.Height != tt.wantHeight {
+				t.Errorf("expected %dx%d, got %dx%d", tt.wantWidth, tt.wantHeight, config.Width, config.Height)
+			}
+		})
+	}
+}
+
+func TestNewImageContentBlockFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	err := os.WriteFile(path, encodeTestImage(t, 10, 10, MediaTypeJPEG), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	block, err := NewImageContentBlockFromFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if block.(ImageContentBlock).Source.MediaType != MediaTypeJPEG {
+		t.Errorf("expected a jpeg image, got %+v", block)
+	}
+}
+
+func TestDecodeWEBPConfig(t *testing.T) {
+	// VP8X header of a 300x200 image, dimensions are stored minus one
+	header := []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x00\x00\x00\x00\x2b\x01\x00\xc7\x00\x00")
+
+	mediaType, err := DetectImageMediaType(header)
+	if err != nil || mediaType != MediaTypeWEBP {
+		t.Fatalf("expected a webp image, got %s, %v", mediaType, err)
+	}
+
+	config, err := decodeWEBPConfig(header)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Width != 300 || config.Height != 200 {
+		t.Errorf("expected 300x200, got %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestImageURLContentBlockJSON(t *testing.T) {
+	data, err := json.Marshal(NewImageURLContentBlock("https://example.com/image.jpg"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"type":"image","source":{"type":"url","url":"https://example.com/image.jpg"}}`
+	if string(data) != expected {
+		t.Errorf("got %s, want %s", data, expected)
+	}
+}
+
+func TestValidateImages(t *testing.T) {
+	request := func(blocks ...ContentBlock) *MessageRequest {
+		return &MessageRequest{
+			Model:    Claude35Sonnet,
+			Messages: []MessagePartRequest{{Role: "user", Content: blocks}},
+		}
+	}
+
+	tooLarge := NewImageContentBlock(MediaTypePNG, strings.Repeat("A", base64.StdEncoding.EncodedLen(MaxImageBytes+1)))
+	tooWide := NewImageContentBlock(MediaTypePNG, base64.StdEncoding.EncodeToString(encodeTestImage(t, MaxImageDimension+1, 1, MediaTypePNG)))
+
+	tests := []struct {
+		name    string
+		request *MessageRequest
+		expErr  string
+	}{
+		{"url image", request(NewImageURLContentBlock("https://example.com/image.jpg")), ""},
+		{"unsupported media type", request(NewImageContentBlock("image/bmp", "Qk0=")), "image 1 has unsupported media type image/bmp"},
+		{"too large", request(NewTextContentBlock("look"), tooLarge), "image 1 is 5242881 bytes, maximum is 5242880"},
+		{"too wide", request(tooWide), "image 1 is 8001x1 pixels, maximum is 8000x8000"},
+		{"tool result unsupported media type", request(NewTextContentBlock("look"), NewToolResultContentBlock("toolu_1", []ContentBlock{NewImageContentBlock("image/bmp", "Qk0=")}, false)), "image 1 has unsupported media type image/bmp"},
+		{"tool result too large", request(NewImageURLContentBlock("https://example.com/image.jpg"), NewToolResultContentBlock("toolu_1", []ContentBlock{tooLarge}, false)), "image 2 is 5242881 bytes, maximum is 5242880"},
+		{"tool result too wide", request(NewToolResultContentBlock("toolu_1", []ContentBlock{NewTextContentBlock("screenshot"), tooWide}, false)), "image 1 is 8001x1 pixels, maximum is 8000x8000"},
+		{"tool result text", request(NewToolResultContentBlock("toolu_1", "done", false)), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessageRequest(tt.request)
+			if tt.expErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.expErr {
+				t.Errorf("expected error %s, got %v", tt.expErr, err)
+			}
+		})
+	}
+}
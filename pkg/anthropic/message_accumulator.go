@@ -0,0 +1,137 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+)
+
+// MessageStreamAccumulator rebuilds the MessageResponse that Message would have returned from the
+// events delivered by MessageStream, so streaming and non-streaming code can share the same logic.
+//
+// A MessageStreamAccumulator is not safe for concurrent use; create one per stream.
+type MessageStreamAccumulator struct {
+	response MessageResponse
+	// blocks holds the blocks by index, as received on content_block_start until they are stopped.
+	blocks    []*MessageStreamContentBlock
+	assembler *contentBlockAssembler
+}
+
+// NewMessageStreamAccumulator creates a MessageStreamAccumulator for a new message stream.
+func NewMessageStreamAccumulator() *MessageStreamAccumulator {
+	return &MessageStreamAccumulator{assembler: newContentBlockAssembler()}
+}
+
+// Add folds a single stream event into the accumulated response.
+func (a *MessageStreamAccumulator) Add(event *MessageStreamResponse) error {
+	if event == nil {
+		return nil
+	}
+
+	switch MessageEventType(event.Type) {
+	case MessageEventTypeMessageStart:
+		if event.Message != nil {
+			a.response.ID = event.Message.ID
+			a.response.Type = event.Message.Type
+			a.response.Model = event.Message.Model
+			a.response.Role = event.Message.Role
+		}
+		a.response.Usage = MessageUsage(event.Usage)
+		a.response.RateLimit = event.RateLimit
+		a.response.RequestID = event.RequestID
+	case MessageEventTypeContentBlockStart:
+		if event.ContentBlock == nil {
+			return fmt.Errorf("content block %d started without a content block", event.Index)
+		}
+
+		for len(a.blocks) <= event.Index {
+			a.blocks = append(a.blocks, nil)
+		}
+		a.blocks[event.Index] = event.ContentBlock
+		a.assembler.start(event.Index, *event.ContentBlock)
+	case MessageEventTypeContentBlockDelta:
+		if !a.assembler.delta(event.Index, event.Delta) {
+			return fmt.Errorf("received delta for content block %d before it was started", event.Index)
+		}
+	case MessageEventTypeContentBlockStop:
+		block, err := a.assembler.stop(event.Index)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return fmt.Errorf("received stop for content block %d before it was started", event.Index)
+		}
+		a.blocks[event.Index] = block
+	case MessageEventTypeMessageDelta:
+		a.response.StopReason = event.Delta.StopReason
+		a.response.StopSequence = event.Delta.StopSequence
+		a.response.Usage.OutputTokens = event.Usage.OutputTokens
+	}
+
+	return nil
+}
+
+// Response returns the response accumulated so far.
+func (a *MessageStreamAccumulator) Response() *MessageResponse {
+	response := a.response
+	response.Content = []ContentBlock{}
+	for index, block := range a.blocks {
+		// blocks that are still streaming are returned with what has been received so far
+		if streaming, ok := a.assembler.current(index); ok {
+			block = streaming
+		}
+		if block == nil {
+			continue
+		}
+
+		response.Content = append(response.Content, block.contentBlock())
+	}
+	return &response
+}
+
+// AccumulateMessageStream consumes the channels returned by MessageStream until the stream ends
+// and returns the complete MessageResponse. When it returns early, on ctx being done or an invalid
+// event, the rest of the stream is drained in the background so its producer isn't blocked.
+func AccumulateMessageStream(
+	ctx context.Context,
+	msCh <-chan *MessageStreamResponse,
+	errCh <-chan error,
+) (*MessageResponse, error) {
+	accumulator := NewMessageStreamAccumulator()
+
+	for {
+		select {
+		case <-ctx.Done():
+			go drainMessageStream(msCh, errCh)
+			return nil, ctx.Err()
+		case event, ok := <-msCh:
+			if !ok {
+				if err := <-errCh; err != nil {
+					return nil, err
+				}
+				return accumulator.Response(), nil
+			}
+
+			err := accumulator.Add(event)
+			if err != nil {
+				go drainMessageStream(msCh, errCh)
+				return nil, err
+			}
+		}
+	}
+}
+
+// drainMessageStream discards the remaining events and error of a stream.
+func drainMessageStream(msCh <-chan *MessageStreamResponse, errCh <-chan error) {
+	for msCh != nil || errCh != nil {
+		select {
+		case _, ok := <-msCh:
+			if !ok {
+				msCh = nil
+			}
+		case _, ok := <-errCh:
+			if !ok {
+				errCh = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMessageStreamAccumulator(t *testing.T) {
+	events := []struct {
+		eventType MessageEventType
+		event     string
+	}{
+		{MessageEventTypeMessageStart, `{"type": "message_start", "message": {"id": "msg_01", "type": "message", "role": "assistant", "content": [], "model": "claude-3-opus-20240229", "stop_reason": null, "stop_sequence": null, "usage": {"input_tokens": 25, "output_tokens": 1}}}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`},
+		{MessageEventTypePing, `{"type": "ping"}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Checking the "}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "weather."}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 0}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 1, "content_block": {"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {}}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "{\"city\": "}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "\"Charleston\"}"}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 1}`},
+		{MessageEventTypeMessageDelta, `{"type": "message_delta", "delta": {"stop_reason": "tool_use", "stop_sequence": null}, "usage": {"output_tokens": 42}}`},
+		{MessageEventTypeMessageStop, `{"type": "message_stop"}`},
+	}
+
+	accumulator := NewMessageStreamAccumulator()
+	for _, test := range events {
+		event, err := ParseMessageEvent(test.eventType, test.event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err = accumulator.Add(event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := &MessageResponse{
+		ID:    "msg_01",
+		Type:  "message",
+		Model: "claude-3-opus-20240229",
+		Role:  "assistant",
+		Content: []ContentBlock{
+			TextContentBlock{Type: "text", Text: "Checking the weather."},
+			ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{"city": "Charleston"}},
+		},
+		StopReason: "tool_use",
+		Usage: MessageUsage{
+			InputTokens:  25,
+			OutputTokens: 42,
+		},
+	}
+
+	response := accumulator.Response()
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("unexpected response, got: %+v, want: %+v", response, expected)
+	}
+}
+
+func TestMessageStreamAccumulatorDeltaBeforeStart(t *testing.T) {
+	accumulator := NewMessageStreamAccumulator()
+
+	err := accumulator.Add(&MessageStreamResponse{
+		Type:  string(MessageEventTypeContentBlockDelta),
+		Index: 3,
+		Delta: MessageStreamDelta{Type: ContentBlockDeltaTypeText, Text: "orphan"},
+	})
+	if err == nil {
+		t.Error("expected error for delta before content_block_start, got nil")
+	}
+}
+
+func TestAccumulateMessageStream(t *testing.T) {
+	msCh := make(chan *MessageStreamResponse, 4)
+	errCh := make(chan error, 1)
+
+	msCh <- &MessageStreamResponse{Type: "message_start", Message: &MessageResponse{ID: "msg_01"}, Usage: MessageStreamUsage{InputTokens: 5}}
+	msCh <- &MessageStreamResponse{Type: "content_block_start", ContentBlock: &MessageStreamContentBlock{Type: "text"}}
+	msCh <- &MessageStreamResponse{Type: "content_block_delta", Delta: MessageStreamDelta{Type: "text_delta", Text: "hello"}}
+	msCh <- &MessageStreamResponse{Type: "content_block_stop"}
+	close(msCh)
+	close(errCh)
+
+	response, err := AccumulateMessageStream(context.Background(), msCh, errCh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.ID != "msg_01" || response.Usage.InputTokens != 5 {
+		t.Errorf("unexpected response metadata, got: %+v", response)
+	}
+
+	if len(response.Content) != 1 || response.Text() != "hello" {
+		t.Errorf("unexpected response content, got: %+v", response.Content)
+	}
+}
+
+func TestAccumulateMessageStreamDrainsOnError(t *testing.T) {
+	msCh := make(chan *MessageStreamResponse)
+	errCh := make(chan error)
+	done := make(chan struct{})
+
+	// an unbuffered producer, like the clients', blocks until every event is received
+	go func() {
+		defer close(done)
+		defer close(errCh)
+		defer close(msCh)
+
+		msCh <- &MessageStreamResponse{Type: "content_block_delta", Index: 2, Delta: MessageStreamDelta{Type: "text_delta", Text: "orphan"}}
+		for i := 0; i < 10; i++ {
+			msCh <- &MessageStreamResponse{Type: "ping"}
+		}
+		errCh <- errors.New("stream failed")
+	}()
+
+	_, err := AccumulateMessageStream(context.Background(), msCh, errCh)
+	if err == nil || err.Error() != "received delta for content block 2 before it was started" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the producer to be drained")
+	}
+}
@@ -3,6 +3,7 @@ package anthropic
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 type MessageEvent struct {
@@ -20,8 +21,10 @@ type MessageStartEvent struct {
 		StopReason   string        `json:"stop_reason"`
 		StopSequence string        `json:"stop_sequence"`
 		Usage        struct {
-			InputTokens  int `json:"input_tokens"`
-			OutputTokens int `json:"output_tokens"`
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 		} `json:"usage"`
 	} `json:"message"`
 }
@@ -30,8 +33,15 @@ type ContentBlockStartEvent struct {
 	MessageEvent
 	Index        int `json:"index"`
 	ContentBlock struct {
-		Type         string `json:"type"`
-		Text         string `json:"text"`
+		Type         string                 `json:"type"`
+		Text         string                 `json:"text"`
+		ID           string                 `json:"id"`
+		Name         string                 `json:"name"`
+		Input        map[string]interface{} `json:"input"`
+		Thinking     string                 `json:"thinking"`
+		Signature    string                 `json:"signature"`
+		Data         string                 `json:"data"`
+		Citations    []Citation             `json:"citations"`
 		CacheControl struct {
 			Type string `json:"type,omitempty"`
 		} `json:"cache_control,omitempty"`
@@ -46,8 +56,12 @@ type ContentBlockDeltaEvent struct {
 	MessageEvent
 	Index int `json:"index"`
 	Delta struct {
-		Type         string `json:"type"`
-		Text         string `json:"text"`
+		Type         string    `json:"type"`
+		Text         string    `json:"text"`
+		PartialJSON  string    `json:"partial_json"`
+		Thinking     string    `json:"thinking"`
+		Signature    string    `json:"signature"`
+		Citation     *Citation `json:"citation"`
 		CacheControl struct {
 			Type string `json:"type,omitempty"`
 		} `json:"cache_control,omitempty"`
@@ -102,11 +116,31 @@ func ParseMessageEvent(eventType MessageEventType, event string) (*MessageStream
 
 		messageStreamResponse.Type = messageStartEvent.Type
 		messageStreamResponse.Usage = messageStartEvent.Message.Usage
+		messageStreamResponse.Message = &MessageResponse{
+			ID:    messageStartEvent.Message.ID,
+			Type:  messageStartEvent.Message.Type,
+			Model: messageStartEvent.Message.Model,
+			Role:  messageStartEvent.Message.Role,
+			Usage: MessageUsage(messageStartEvent.Message.Usage),
+		}
 	case MessageEventTypeContentBlockStart:
 		contentBlockEvent := &ContentBlockStartEvent{}
 		err = json.Unmarshal([]byte(event), &contentBlockEvent)
 
 		messageStreamResponse.Type = contentBlockEvent.Type
+		messageStreamResponse.Index = contentBlockEvent.Index
+		messageStreamResponse.ContentBlock = &MessageStreamContentBlock{
+			Type:  contentBlockEvent.ContentBlock.Type,
+			Text:  contentBlockEvent.ContentBlock.Text,
+			ID:    contentBlockEvent.ContentBlock.ID,
+			Name:  contentBlockEvent.ContentBlock.Name,
+			Input: contentBlockEvent.ContentBlock.Input,
+
+			Thinking:  contentBlockEvent.ContentBlock.Thinking,
+			Signature: contentBlockEvent.ContentBlock.Signature,
+			Data:      contentBlockEvent.ContentBlock.Data,
+			Citations: contentBlockEvent.ContentBlock.Citations,
+		}
 	case MessageEventTypePing:
 		pingEvent := &PingEvent{}
 		err = json.Unmarshal([]byte(event), &pingEvent)
@@ -117,13 +151,19 @@ func ParseMessageEvent(eventType MessageEventType, event string) (*MessageStream
 		err = json.Unmarshal([]byte(event), &contentBlockEvent)
 
 		messageStreamResponse.Type = contentBlockEvent.Type
+		messageStreamResponse.Index = contentBlockEvent.Index
 		messageStreamResponse.Delta.Type = contentBlockEvent.Delta.Type
 		messageStreamResponse.Delta.Text = contentBlockEvent.Delta.Text
+		messageStreamResponse.Delta.PartialJSON = contentBlockEvent.Delta.PartialJSON
+		messageStreamResponse.Delta.Thinking = contentBlockEvent.Delta.Thinking
+		messageStreamResponse.Delta.Signature = contentBlockEvent.Delta.Signature
+		messageStreamResponse.Delta.Citation = contentBlockEvent.Delta.Citation
 	case MessageEventTypeContentBlockStop:
 		contentBlockStopEvent := &ContentBlockStopEvent{}
 		err = json.Unmarshal([]byte(event), &contentBlockStopEvent)
 
 		messageStreamResponse.Type = contentBlockStopEvent.Type
+		messageStreamResponse.Index = contentBlockStopEvent.Index
 	case MessageEventTypeMessageDelta:
 		messageDeltaEvent := &MessageDeltaEvent{}
 		err = json.Unmarshal([]byte(event), &messageDeltaEvent)
@@ -144,15 +184,159 @@ func ParseMessageEvent(eventType MessageEventType, event string) (*MessageStream
 			return messageStreamResponse, err
 		}
 
-		// error received on stream
-		return messageStreamResponse, fmt.Errorf(
-			"error type: %s, message: %s",
-			messageErrorEvent.Error.Type,
-			messageErrorEvent.Error.Message,
-		)
+		// error received on stream, it has no status code and is matched by its type
+		return messageStreamResponse, &APIError{
+			Type:    messageErrorEvent.Error.Type,
+			Message: messageErrorEvent.Error.Message,
+			Body:    []byte(event),
+		}
 	default:
 		err = UnsupportedEventType{Msg: "unknown event type"}
 	}
 
 	return messageStreamResponse, err
 }
+
+// MessageEventParser parses the events of a single message stream. Unlike ParseMessageEvent it
+// keeps track of the content blocks started on the stream, so that content_block_stop events
+// carry the fully assembled block, including the parsed input of tool_use blocks.
+//
+// A MessageEventParser is not safe for concurrent use; create one per stream.
+type MessageEventParser struct {
+	blocks *contentBlockAssembler
+}
+
+// NewMessageEventParser creates a MessageEventParser for a new message stream.
+func NewMessageEventParser() *MessageEventParser {
+	return &MessageEventParser{blocks: newContentBlockAssembler()}
+}
+
+// Parse parses a single event of the stream, see ParseMessageEvent.
+func (p *MessageEventParser) Parse(eventType MessageEventType, event string) (*MessageStreamResponse, error) {
+	messageStreamResponse, err := ParseMessageEvent(eventType, event)
+	if err != nil {
+		return messageStreamResponse, err
+	}
+
+	index := messageStreamResponse.Index
+
+	switch eventType {
+	case MessageEventTypeContentBlockStart:
+		p.blocks.start(index, *messageStreamResponse.ContentBlock)
+	case MessageEventTypeContentBlockDelta:
+		p.blocks.delta(index, messageStreamResponse.Delta)
+	case MessageEventTypeContentBlockStop:
+		block, err := p.blocks.stop(index)
+		if err != nil {
+			return messageStreamResponse, err
+		}
+		if block != nil {
+			messageStreamResponse.ContentBlock = block
+		}
+	}
+
+	return messageStreamResponse, nil
+}
+
+// contentBlockAssembler assembles the content blocks of a stream from their start, delta and stop
+// events, for MessageEventParser and MessageStreamAccumulator.
+type contentBlockAssembler struct {
+	blocks      map[int]*MessageStreamContentBlock
+	text        map[int]*strings.Builder
+	partialJSON map[int]*strings.Builder
+}
+
+func newContentBlockAssembler() *contentBlockAssembler {
+	return &contentBlockAssembler{
+		blocks:      map[int]*MessageStreamContentBlock{},
+		text:        map[int]*strings.Builder{},
+		partialJSON: map[int]*strings.Builder{},
+	}
+}
+
+// start begins assembling the block at index.
+func (a *contentBlockAssembler) start(index int, block MessageStreamContentBlock) {
+	a.blocks[index] = &block
+	a.text[index] = &strings.Builder{}
+	a.text[index].WriteString(block.streamedText())
+	a.partialJSON[index] = &strings.Builder{}
+}
+
+// delta adds delta to the block at index, reporting false when no block was started at index.
+func (a *contentBlockAssembler) delta(index int, delta MessageStreamDelta) bool {
+	block, ok := a.blocks[index]
+	if !ok {
+		return false
+	}
+
+	switch delta.Type {
+	case ContentBlockDeltaTypeText:
+		a.text[index].WriteString(delta.Text)
+	case ContentBlockDeltaTypeThinking:
+		a.text[index].WriteString(delta.Thinking)
+	case ContentBlockDeltaTypeSignature:
+		block.Signature = delta.Signature
+	case ContentBlockDeltaTypeCitations:
+		block.addCitation(delta.Citation)
+	case ContentBlockDeltaTypeInputJSON:
+		a.partialJSON[index].WriteString(delta.PartialJSON)
+	}
+
+	return true
+}
+
+// stop completes the block at index, parsing the input of tool_use blocks. It returns nil when no
+// block was started at index.
+func (a *contentBlockAssembler) stop(index int) (*MessageStreamContentBlock, error) {
+	block, ok := a.blocks[index]
+	if !ok {
+		return nil, nil
+	}
+
+	block.setStreamedText(a.text[index].String())
+	if block.Type == "tool_use" {
+		input, err := parseToolInput(block.Input, a.partialJSON[index].String())
+		if err != nil {
+			return nil, fmt.Errorf("error parsing input of content block %d: %w", index, err)
+		}
+		block.Input = input
+	}
+
+	delete(a.blocks, index)
+	delete(a.text, index)
+	delete(a.partialJSON, index)
+
+	return block, nil
+}
+
+// current returns a copy of the block being assembled at index with the text received so far,
+// reporting false when no block is being assembled at index.
+func (a *contentBlockAssembler) current(index int) (*MessageStreamContentBlock, bool) {
+	block, ok := a.blocks[index]
+	if !ok {
+		return nil, false
+	}
+
+	streaming := *block
+	streaming.setStreamedText(a.text[index].String())
+	return &streaming, true
+}
+
+// parseToolInput assembles the input of a tool_use block from its accumulated partial JSON,
+// falling back to the input sent with content_block_start when no fragments were received.
+func parseToolInput(initial map[string]interface{}, partialJSON string) (map[string]interface{}, error) {
+	if strings.TrimSpace(partialJSON) == "" {
+		if initial == nil {
+			return map[string]interface{}{}, nil
+		}
+		return initial, nil
+	}
+
+	input := map[string]interface{}{}
+	err := json.Unmarshal([]byte(partialJSON), &input)
+	if err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
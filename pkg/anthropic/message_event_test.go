@@ -1,6 +1,7 @@
 package anthropic
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -26,15 +27,31 @@ func TestParseMessageEvent(t *testing.T) {
 					"stop_sequence": "",
 					"usage": {
 						"input_tokens": 10,
-						"output_tokens": 20
+						"output_tokens": 20,
+						"cache_creation_input_tokens": 30,
+						"cache_read_input_tokens": 40
 					}
 				}
 			}`,
 			expected: &MessageStreamResponse{
 				Type: "message_start",
+				Message: &MessageResponse{
+					ID:    "123",
+					Type:  "text",
+					Role:  "user",
+					Model: "claude-v2_1",
+					Usage: MessageUsage{
+						InputTokens:              10,
+						OutputTokens:             20,
+						CacheCreationInputTokens: 30,
+						CacheReadInputTokens:     40,
+					},
+				},
 				Usage: MessageStreamUsage{
-					InputTokens:  10,
-					OutputTokens: 20,
+					InputTokens:              10,
+					OutputTokens:             20,
+					CacheCreationInputTokens: 30,
+					CacheReadInputTokens:     40,
 				},
 			},
 		},
@@ -49,7 +66,12 @@ func TestParseMessageEvent(t *testing.T) {
 				}
 			}`,
 			expected: &MessageStreamResponse{
-				Type: "content_block_start",
+				Type:  "content_block_start",
+				Index: 1,
+				ContentBlock: &MessageStreamContentBlock{
+					Type: "text",
+					Text: "This is a content block",
+				},
 			},
 		},
 		{
@@ -131,7 +153,7 @@ func TestParseMessageEvent(t *testing.T) {
 				}
 			}`,
 			expected:  &MessageStreamResponse{},
-			expErrStr: "error type: error, message: This is an error",
+			expErrStr: "unknown error occurred: This is an error (type error)",
 		},
 	}
 
@@ -151,6 +173,19 @@ func TestParseMessageEvent(t *testing.T) {
 	}
 }
 
+func TestParseMessageEventAPIError(t *testing.T) {
+	_, err := ParseMessageEvent(MessageEventTypeError, `{"type": "error", "error": {"type": "rate_limit_error", "message": "Too many requests"}}`)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Type != "rate_limit_error" || apiErr.Message != "Too many requests" {
+		t.Fatalf("expected an APIError, got %#v", err)
+	}
+
+	if !errors.Is(err, ErrAnthropicRateLimit) {
+		t.Errorf("expected %v to match %v", err, ErrAnthropicRateLimit)
+	}
+}
+
 func TestUnsupportedEventType(t *testing.T) {
 	res, err := ParseMessageEvent(MessageEventType("not-a-real-type"), "")
 	if err == nil {
@@ -161,3 +196,75 @@ func TestUnsupportedEventType(t *testing.T) {
 		t.Errorf("unexpected error, got: %v", err)
 	}
 }
+
+func TestMessageEventParserToolUse(t *testing.T) {
+	events := []struct {
+		eventType MessageEventType
+		event     string
+	}{
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Let me check "}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "the weather."}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 0}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 1, "content_block": {"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {}}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": ""}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "{\"city\": \"Charl"}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "eston\", \"unit\": \"fahrenheit\"}"}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 1}`},
+	}
+
+	parser := NewMessageEventParser()
+	responses := []*MessageStreamResponse{}
+	for _, test := range events {
+		response, err := parser.Parse(test.eventType, test.event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		responses = append(responses, response)
+	}
+
+	start := responses[4]
+	if start.Index != 1 || start.ContentBlock == nil || start.ContentBlock.ID != "toolu_01" || start.ContentBlock.Name != "get_weather" {
+		t.Errorf("unexpected tool_use start, got: %+v", start.ContentBlock)
+	}
+
+	if responses[6].Index != 1 || responses[6].Delta.PartialJSON != `{"city": "Charl` {
+		t.Errorf("unexpected partial json delta, got: %+v", responses[6].Delta)
+	}
+
+	textStop := responses[3]
+	expectedText := &MessageStreamContentBlock{Type: "text", Text: "Let me check the weather."}
+	if !reflect.DeepEqual(textStop.ContentBlock, expectedText) {
+		t.Errorf("unexpected text block, got: %+v, want: %+v", textStop.ContentBlock, expectedText)
+	}
+
+	toolStop := responses[8]
+	expectedTool := &MessageStreamContentBlock{
+		Type:  "tool_use",
+		ID:    "toolu_01",
+		Name:  "get_weather",
+		Input: map[string]interface{}{"city": "Charleston", "unit": "fahrenheit"},
+	}
+	if !reflect.DeepEqual(toolStop.ContentBlock, expectedTool) {
+		t.Errorf("unexpected tool_use block, got: %+v, want: %+v", toolStop.ContentBlock, expectedTool)
+	}
+}
+
+func TestMessageEventParserInvalidToolInput(t *testing.T) {
+	parser := NewMessageEventParser()
+
+	_, err := parser.Parse(MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 0, "content_block": {"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {}}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = parser.Parse(MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "{\"city\": "}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = parser.Parse(MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 0}`)
+	if err == nil {
+		t.Error("expected error for truncated tool input, got nil")
+	}
+}
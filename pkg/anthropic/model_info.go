@@ -0,0 +1,104 @@
+package anthropic
+
+import (
+	"strings"
+	"time"
+)
+
+// ModelInfo describes a model available through the API.
+type ModelInfo struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Model returns the Model identified by the info, for use in requests.
+func (i ModelInfo) Model() Model {
+	return Model(i.ID)
+}
+
+// Capabilities returns the capabilities RegisterModelInfo registers for the model. The models API
+// doesn't describe them, so they are taken from the closest known model of the same family, e.g.
+// claude-3-7-sonnet-latest for claude-3-7-sonnet-20250301, leaving out its Bedrock availability and
+// deprecation. A model of an unknown family is assumed to support messages with images and tools;
+// use RegisterModel to describe it fully.
+func (i ModelInfo) Capabilities() ModelCapabilities {
+	capabilities, ok := familyCapabilities(modelFamily(i.ID))
+	if !ok {
+		return ModelCapabilities{Message: true, Image: true, ToolUse: true, Discovered: true}
+	}
+
+	capabilities.Discovered = true
+	capabilities.Complete = false
+	capabilities.BedrockModelID = ""
+	capabilities.BedrockCrossRegion = false
+	capabilities.DeprecatedAt = time.Time{}
+	return capabilities
+}
+
+// modelFamily returns the id of a model without its date or latest suffix, e.g. claude-3-7-sonnet
+// for claude-3-7-sonnet-20250219.
+func modelFamily(id string) string {
+	index := strings.LastIndex(id, "-")
+	if index < 0 {
+		return id
+	}
+
+	suffix := id[index+1:]
+	if suffix == "latest" || (len(suffix) == 8 && strings.Trim(suffix, "0123456789") == "") {
+		return id[:index]
+	}
+	return id
+}
+
+// familyCapabilities returns the capabilities of the latest alias of family, or of its most recent
+// registered version when it has no alias.
+func familyCapabilities(family string) (ModelCapabilities, bool) {
+	if capabilities, ok := Model(family + "-latest").Capabilities(); ok {
+		return capabilities, true
+	}
+
+	modelRegistry.RLock()
+	defer modelRegistry.RUnlock()
+
+	var closest Model
+	for model := range modelRegistry.models {
+		if modelFamily(string(model)) == family && model > closest {
+			closest = model
+		}
+	}
+
+	if closest == "" {
+		return ModelCapabilities{}, false
+	}
+	return modelRegistry.models[closest], true
+}
+
+// RegisterModelInfo registers a model discovered through the models API. Models already known to
+// the library are left unchanged.
+func RegisterModelInfo(info ModelInfo) {
+	if info.Model().IsValid() {
+		return
+	}
+
+	RegisterModel(info.Model(), info.Capabilities())
+}
+
+// ListModelsOptions pages through the available models, most recently released first.
+type ListModelsOptions struct {
+	// BeforeID returns the page of models right before this model id.
+	BeforeID string
+	// AfterID returns the page of models right after this model id.
+	AfterID string
+	// Limit is the number of models per page, between 1 and 1000 (defaults to 20).
+	Limit int
+}
+
+// ModelList is a page of models.
+type ModelList struct {
+	Data    []ModelInfo `json:"data"`
+	HasMore bool        `json:"has_more"`
+	FirstID string      `json:"first_id"`
+	LastID  string      `json:"last_id"`
+}
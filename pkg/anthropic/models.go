@@ -1,5 +1,10 @@
 package anthropic
 
+import (
+	"sync"
+	"time"
+)
+
 // Model represents a Claude model.
 type Model string
 
@@ -8,13 +13,19 @@ const DefaultModel = Claude3Sonnet
 
 // https://docs.anthropic.com/claude/docs/models-overview
 const (
-	// Highest level of intelligence and capability
+	// Highest level of intelligence and capability, with extended thinking
+	Claude37Sonnet Model = "claude-3-7-sonnet-latest"
+
+	// Claude 3.7 Sonnet, 20250219 model
+	Claude37Sonnet_20250219 Model = "claude-3-7-sonnet-20250219"
+
+	// Former highest level of intelligence and capability
 	Claude35Sonnet Model = "claude-3-5-sonnet-latest"
 
 	// New version of claude-3-5-sonnet
 	Claude35Sonnet_20241022 Model = "claude-3-5-sonnet-20241022"
 
-	// Former highest level of intelligence and capability
+	// Original version of claude-3-5-sonnet
 	Claude35Sonnet_20240620 Model = "claude-3-5-sonnet-20240620"
 
 	// Fastest and most compact model for near-instant responsiveness
@@ -48,67 +59,256 @@ const (
 	Claude3Haiku  Model = "claude-3-haiku-20240307"
 )
 
-// Define the imageCompatibleModels map alongside the other maps
+// ModelPricing is the price of a model's tokens, in US dollars per million tokens.
+type ModelPricing struct {
+	Input  float64
+	Output float64
+	// CacheWrite and CacheRead are 0 when the model doesn't support prompt caching.
+	CacheWrite float64
+	CacheRead  float64
+}
+
+// Cost returns the price in US dollars of the tokens counted by usage.
+func (p ModelPricing) Cost(usage MessageUsage) float64 {
+	return (float64(usage.InputTokens)*p.Input +
+		float64(usage.OutputTokens)*p.Output +
+		float64(usage.CacheCreationInputTokens)*p.CacheWrite +
+		float64(usage.CacheReadInputTokens)*p.CacheRead) / 1e6
+}
+
+// ModelCapabilities describes a model: the endpoints and features it supports, its limits, how it
+// is reached on Bedrock, and its pricing.
+type ModelCapabilities struct {
+	Message  bool
+	Complete bool
+	// Image reports whether the model accepts image content.
+	Image       bool
+	ToolUse     bool
+	ComputerUse bool
+	// ComputerUseBeta is the beta of the version of the computer use tools the model supports,
+	// e.g. ComputerUseBeta_20250124, empty when ComputerUse is false.
+	ComputerUseBeta string
+	// Thinking reports whether the model supports extended thinking.
+	Thinking bool
+
+	MaxOutputTokens int
+	ContextWindow   int
+
+	// BedrockModelID is the id of the model on Bedrock, empty when it isn't available there.
+	BedrockModelID string
+	// BedrockCrossRegion reports whether the model is available through cross-region inference.
+	BedrockCrossRegion bool
+
+	// DeprecatedAt is the date the model was deprecated, zero while it is supported.
+	DeprecatedAt time.Time
+	Pricing      ModelPricing
+
+	// Discovered reports whether the capabilities were inferred for a model found through the
+	// models API rather than known to the library.
+	Discovered bool
+}
+
+// Deprecated reports whether the model has been deprecated.
+func (c ModelCapabilities) Deprecated() bool {
+	return !c.DeprecatedAt.IsZero()
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
 var (
-	imageCompatibleModels = map[Model]bool{
-		Claude3Opus:             true,
-		Claude3Sonnet:           true,
-		Claude3Haiku:            true,
-		Claude35Sonnet:          true,
-		Claude35Sonnet_20241022: true,
-		Claude35Sonnet_20240620: true,
+	claude37Sonnet = ModelCapabilities{
+		Message:            true,
+		Image:              true,
+		ToolUse:            true,
+		ComputerUse:        true,
+		ComputerUseBeta:    ComputerUseBeta_20250124,
+		Thinking:           true,
+		MaxOutputTokens:    64000,
+		ContextWindow:      200000,
+		BedrockModelID:     "anthropic.claude-3-7-sonnet-20250219-v1:0",
+		BedrockCrossRegion: true,
+		Pricing:            ModelPricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3},
 	}
 
-	messageCompatibleModels = map[Model]bool{
-		Claude35Sonnet:          true,
-		Claude35Sonnet_20241022: true,
-		Claude35Sonnet_20240620: true,
-		Claude3Opus:             true,
-		Claude3Sonnet:           true,
-		Claude3Haiku:            true,
-		ClaudeV2_1:              true,
+	claude35SonnetV2 = ModelCapabilities{
+		Message:            true,
+		Image:              true,
+		ToolUse:            true,
+		ComputerUse:        true,
+		ComputerUseBeta:    ComputerUseBeta,
+		MaxOutputTokens:    8192,
+		ContextWindow:      200000,
+		BedrockModelID:     "anthropic.claude-3-5-sonnet-20241022-v2:0",
+		BedrockCrossRegion: true,
+		DeprecatedAt:       date(2025, time.August, 13),
+		Pricing:            ModelPricing{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3},
 	}
 
-	completeCompatibleModels = map[Model]bool{
-		Claude35Sonnet:         true,
-		Claude3Opus:            true,
-		Claude3Sonnet:          true,
-		Claude3Haiku:           true,
-		ClaudeV2_1:             true,
-		ClaudeV2:               true,
-		ClaudeV1:               true,
-		ClaudeV1_100k:          true,
-		ClaudeInstantV1:        true,
-		ClaudeInstantV1_100k:   true,
-		ClaudeV1_3:             true,
-		ClaudeV1_3_100k:        true,
-		ClaudeV1_2:             true,
-		ClaudeV1_0:             true,
-		ClaudeInstantV1_1:      true,
-		ClaudeInstantV1_1_100k: true,
-		ClaudeInstantV1_0:      true,
+	claude35Haiku = ModelCapabilities{
+		Message:            true,
+		ToolUse:            true,
+		MaxOutputTokens:    8192,
+		ContextWindow:      200000,
+		BedrockModelID:     "anthropic.claude-3-5-haiku-20241022-v1:0",
+		BedrockCrossRegion: true,
+		Pricing:            ModelPricing{Input: 0.8, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+	}
+
+	claude2 = ModelCapabilities{
+		Complete:        true,
+		MaxOutputTokens: 4096,
+		ContextWindow:   100000,
+		DeprecatedAt:    date(2025, time.January, 21),
+		Pricing:         ModelPricing{Input: 8, Output: 24},
+	}
+
+	claude1 = ModelCapabilities{
+		Complete:        true,
+		MaxOutputTokens: 4096,
+		ContextWindow:   9000,
+		DeprecatedAt:    date(2024, time.September, 4),
+		Pricing:         ModelPricing{Input: 8, Output: 24},
+	}
+
+	claudeInstant1 = ModelCapabilities{
+		Complete:        true,
+		MaxOutputTokens: 4096,
+		ContextWindow:   9000,
+		DeprecatedAt:    date(2024, time.September, 4),
+		Pricing:         ModelPricing{Input: 0.8, Output: 2.4},
 	}
 )
 
+// with returns a copy of c modified by update, to describe variants of a model.
+func (c ModelCapabilities) with(update func(*ModelCapabilities)) ModelCapabilities {
+	update(&c)
+	return c
+}
+
+func withContextWindow(contextWindow int) func(*ModelCapabilities) {
+	return func(c *ModelCapabilities) {
+		c.ContextWindow = contextWindow
+	}
+}
+
+// modelRegistry describes every known model, the built-in ones and those added by RegisterModel.
+var modelRegistry = struct {
+	sync.RWMutex
+	models map[Model]ModelCapabilities
+}{
+	models: map[Model]ModelCapabilities{
+		Claude37Sonnet:          claude37Sonnet,
+		Claude37Sonnet_20250219: claude37Sonnet,
+
+		// the latest alias is also accepted by the complete endpoint
+		Claude35Sonnet: claude35SonnetV2.with(func(c *ModelCapabilities) {
+			c.Complete = true
+		}),
+		Claude35Sonnet_20241022: claude35SonnetV2,
+		Claude35Sonnet_20240620: claude35SonnetV2.with(func(c *ModelCapabilities) {
+			c.ComputerUse = false
+			c.ComputerUseBeta = ""
+			c.BedrockModelID = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+		}),
+		Claude35Haiku:          claude35Haiku,
+		Claude35Haiku_20241022: claude35Haiku,
+
+		Claude3Opus: {
+			Message:            true,
+			Complete:           true,
+			Image:              true,
+			ToolUse:            true,
+			MaxOutputTokens:    4096,
+			ContextWindow:      200000,
+			BedrockModelID:     "anthropic.claude-3-opus-20240229-v1:0",
+			BedrockCrossRegion: true,
+			DeprecatedAt:       date(2025, time.June, 30),
+			Pricing:            ModelPricing{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5},
+		},
+		Claude3Sonnet: {
+			Message:            true,
+			Complete:           true,
+			Image:              true,
+			ToolUse:            true,
+			MaxOutputTokens:    4096,
+			ContextWindow:      200000,
+			BedrockModelID:     "anthropic.claude-3-sonnet-20240229-v1:0",
+			BedrockCrossRegion: true,
+			DeprecatedAt:       date(2025, time.January, 21),
+			Pricing:            ModelPricing{Input: 3, Output: 15},
+		},
+		Claude3Haiku: {
+			Message:            true,
+			Complete:           true,
+			Image:              true,
+			ToolUse:            true,
+			MaxOutputTokens:    4096,
+			ContextWindow:      200000,
+			BedrockModelID:     "anthropic.claude-3-haiku-20240307-v1:0",
+			BedrockCrossRegion: true,
+			Pricing:            ModelPricing{Input: 0.25, Output: 1.25, CacheWrite: 0.3, CacheRead: 0.03},
+		},
+
+		ClaudeV2_1: claude2.with(func(c *ModelCapabilities) {
+			c.Message = true
+			c.ContextWindow = 200000
+			c.BedrockModelID = "anthropic.claude-v2:1"
+		}),
+		ClaudeV2: claude2,
+
+		ClaudeV1:        claude1,
+		ClaudeV1_100k:   claude1.with(withContextWindow(100000)),
+		ClaudeV1_3:      claude1,
+		ClaudeV1_3_100k: claude1.with(withContextWindow(100000)),
+		ClaudeV1_2:      claude1,
+		ClaudeV1_0:      claude1,
+
+		ClaudeInstantV1:        claudeInstant1,
+		ClaudeInstantV1_100k:   claudeInstant1.with(withContextWindow(100000)),
+		ClaudeInstantV1_1:      claudeInstant1,
+		ClaudeInstantV1_1_100k: claudeInstant1.with(withContextWindow(100000)),
+		ClaudeInstantV1_0:      claudeInstant1,
+	},
+}
+
+// RegisterModel adds a model to the registry, or replaces the description of a known model. The
+// compatibility checks, request validation and the Bedrock client all use the registry, so models
+// the library didn't ship with can be used once registered. RegisterModel is safe for concurrent
+// use.
+func RegisterModel(model Model, capabilities ModelCapabilities) {
+	modelRegistry.Lock()
+	defer modelRegistry.Unlock()
+
+	modelRegistry.models[model] = capabilities
+}
+
+// Capabilities returns the description of the model, false if it isn't registered.
+func (m Model) Capabilities() (ModelCapabilities, bool) {
+	modelRegistry.RLock()
+	defer modelRegistry.RUnlock()
+
+	capabilities, ok := modelRegistry.models[m]
+	return capabilities, ok
+}
+
 func (m Model) IsImageCompatible() bool {
-	return imageCompatibleModels[m]
+	capabilities, _ := m.Capabilities()
+	return capabilities.Image
 }
 
 func (m Model) IsMessageCompatible() bool {
-	switch m {
-	case Claude3Opus, Claude3Sonnet, Claude3Haiku, ClaudeV2_1, Claude35Sonnet, Claude35Sonnet_20241022, Claude35Sonnet_20240620, Claude35Haiku, Claude35Haiku_20241022:
-		return true
-	}
-	return false
+	capabilities, _ := m.Capabilities()
+	return capabilities.Message
 }
 
 func (m Model) IsCompleteCompatible() bool {
-	return completeCompatibleModels[m]
+	capabilities, _ := m.Capabilities()
+	return capabilities.Complete
 }
 
 func (m Model) IsValid() bool {
-	_, inImage := imageCompatibleModels[m]
-	_, inMessage := messageCompatibleModels[m]
-	_, inComplete := completeCompatibleModels[m]
-	return inImage || inMessage || inComplete
+	_, ok := m.Capabilities()
+	return ok
 }
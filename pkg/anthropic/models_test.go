@@ -1,6 +1,7 @@
 package anthropic
 
 import (
+	"math"
 	"testing"
 )
 
@@ -13,7 +14,12 @@ func TestModelCompatibility(t *testing.T) {
 		wantComplete bool
 		wantValid    bool
 	}{
+		{"Claude 3.7 Sonnet", Claude37Sonnet, true, true, false, true},
 		{"Claude 3.5 Sonnet", Claude35Sonnet, true, true, true, true},
+		{"Claude 3.5 Sonnet 20241022", Claude35Sonnet_20241022, true, true, false, true},
+		{"Claude 3.5 Sonnet 20240620", Claude35Sonnet_20240620, true, true, false, true},
+		{"Claude 3.5 Haiku", Claude35Haiku, false, true, false, true},
+		{"Claude 3.5 Haiku 20241022", Claude35Haiku_20241022, false, true, false, true},
 		{"Claude 3 Opus", Claude3Opus, true, true, true, true},
 		{"Claude 3 Sonnet", Claude3Sonnet, true, true, true, true},
 		{"Claude 3 Haiku", Claude3Haiku, true, true, true, true},
@@ -56,3 +62,90 @@ func TestDefaultModel(t *testing.T) {
 		t.Errorf("DefaultModel = %v, want %v", DefaultModel, Claude3Sonnet)
 	}
 }
+
+func TestRegisterModel(t *testing.T) {
+	model := Model("claude-registered-test")
+	if model.IsValid() {
+		t.Fatal("expected the model to be unknown before being registered")
+	}
+
+	RegisterModel(model, ModelCapabilities{Message: true})
+
+	if !model.IsValid() || !model.IsMessageCompatible() {
+		t.Error("expected the registered model to be message compatible")
+	}
+	if model.IsImageCompatible() || model.IsCompleteCompatible() {
+		t.Error("expected the registered model to only be message compatible")
+	}
+
+	// registering again replaces the description of the model
+	RegisterModel(model, ModelCapabilities{Message: true, Image: true, MaxOutputTokens: 1024})
+	capabilities, ok := model.Capabilities()
+	if !ok || !capabilities.Image || capabilities.MaxOutputTokens != 1024 {
+		t.Errorf("expected the registered capabilities to be replaced, got %+v", capabilities)
+	}
+}
+
+func TestModelCapabilities(t *testing.T) {
+	capabilities, ok := Claude35Sonnet_20241022.Capabilities()
+	if !ok {
+		t.Fatal("expected the model to be registered")
+	}
+
+	if !capabilities.ToolUse || !capabilities.ComputerUse || capabilities.MaxOutputTokens != 8192 || capabilities.ContextWindow != 200000 {
+		t.Errorf("unexpected capabilities %+v", capabilities)
+	}
+
+	if capabilities.BedrockModelID != "anthropic.claude-3-5-sonnet-20241022-v2:0" || !capabilities.BedrockCrossRegion {
+		t.Errorf("unexpected bedrock capabilities %+v", capabilities)
+	}
+
+	capabilities, _ = ClaudeV2_1.Capabilities()
+	if capabilities.BedrockCrossRegion || !capabilities.Deprecated() {
+		t.Errorf("expected claude-2.1 to be deprecated without cross-region inference, got %+v", capabilities)
+	}
+
+	capabilities, _ = Claude3Haiku.Capabilities()
+	if capabilities.Deprecated() {
+		t.Error("expected claude-3-haiku not to be deprecated")
+	}
+}
+
+func TestModelInfoCapabilities(t *testing.T) {
+	// a new version of a known family is described like its latest alias
+	capabilities := ModelInfo{ID: "claude-3-7-sonnet-20990101"}.Capabilities()
+	if !capabilities.Thinking || capabilities.ComputerUseBeta != ComputerUseBeta_20250124 || capabilities.MaxOutputTokens != 64000 {
+		t.Errorf("expected the capabilities of claude-3-7-sonnet, got %+v", capabilities)
+	}
+	if capabilities.BedrockModelID != "" || capabilities.Deprecated() || !capabilities.Discovered {
+		t.Errorf("expected a discovered model without bedrock model or deprecation, got %+v", capabilities)
+	}
+
+	// families without a latest alias use their most recent version
+	capabilities = ModelInfo{ID: "claude-3-haiku-20990101"}.Capabilities()
+	expected, _ := Claude3Haiku.Capabilities()
+	if capabilities.MaxOutputTokens != expected.MaxOutputTokens || capabilities.Pricing != expected.Pricing || capabilities.Complete {
+		t.Errorf("expected the capabilities of claude-3-haiku, got %+v", capabilities)
+	}
+
+	// an unknown family is assumed to support images and tools
+	capabilities = ModelInfo{ID: "claude-next-20990101"}.Capabilities()
+	if capabilities != (ModelCapabilities{Message: true, Image: true, ToolUse: true, Discovered: true}) {
+		t.Errorf("expected message, image and tool use support, got %+v", capabilities)
+	}
+}
+
+func TestModelPricingCost(t *testing.T) {
+	capabilities, _ := Claude35Sonnet.Capabilities()
+	cost := capabilities.Pricing.Cost(MessageUsage{
+		InputTokens:              1000000,
+		OutputTokens:             100000,
+		CacheCreationInputTokens: 200000,
+		CacheReadInputTokens:     1000000,
+	})
+
+	// 3 input + 1.5 output + 0.75 cache write + 0.3 cache read
+	if math.Abs(cost-5.55) > 1e-9 {
+		t.Errorf("expected a cost of 5.55, got %v", cost)
+	}
+}
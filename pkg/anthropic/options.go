@@ -27,6 +27,14 @@ func WithSystemPrompt(systemPrompt string) MessageRequestOption {
 	}
 }
 
+// WithSystemBlocks sets the system prompt as a list of text blocks, which allows caching parts of
+// it with cache_control.
+func WithSystemBlocks(blocks ...TextContentBlock) MessageRequestOption {
+	return func(r *MessageRequest) {
+		r.SystemBlocks = blocks
+	}
+}
+
 func WithMetadata(metadata interface{}) MessageRequestOption {
 	return func(r *MessageRequest) {
 		r.Metadata = metadata
@@ -71,3 +79,11 @@ func WithMessageTopP(topP float64) MessageRequestOption {
 		r.TopP = topP
 	}
 }
+
+// WithMessageThinking enables extended thinking with a budget of budgetTokens, which must be below
+// the max tokens of the request.
+func WithMessageThinking(budgetTokens int) MessageRequestOption {
+	return func(r *MessageRequest) {
+		r.Thinking = NewThinkingConfig(budgetTokens)
+	}
+}
@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit is the rate limit state reported by the API in the anthropic-ratelimit-* response
+// headers. Limits that weren't reported are left zero.
+type RateLimit struct {
+	RequestsLimit     int
+	RequestsRemaining int
+	RequestsReset     time.Time
+
+	TokensLimit     int
+	TokensRemaining int
+	TokensReset     time.Time
+
+	InputTokensLimit     int
+	InputTokensRemaining int
+	InputTokensReset     time.Time
+
+	OutputTokensLimit     int
+	OutputTokensRemaining int
+	OutputTokensReset     time.Time
+}
+
+// ParseRateLimit parses the anthropic-ratelimit-* headers of a response. It returns nil if the
+// response doesn't carry any.
+func ParseRateLimit(header http.Header) *RateLimit {
+	rateLimit := &RateLimit{}
+	found := false
+
+	limits := []struct {
+		name      string
+		limit     *int
+		remaining *int
+		reset     *time.Time
+	}{
+		{"requests", &rateLimit.RequestsLimit, &rateLimit.RequestsRemaining, &rateLimit.RequestsReset},
+		{"tokens", &rateLimit.TokensLimit, &rateLimit.TokensRemaining, &rateLimit.TokensReset},
+		{"input-tokens", &rateLimit.InputTokensLimit, &rateLimit.InputTokensRemaining, &rateLimit.InputTokensReset},
+		{"output-tokens", &rateLimit.OutputTokensLimit, &rateLimit.OutputTokensRemaining, &rateLimit.OutputTokensReset},
+	}
+
+	for _, limit := range limits {
+		prefix := "anthropic-ratelimit-" + limit.name
+
+		if value, err := strconv.Atoi(header.Get(prefix + "-limit")); err == nil {
+			*limit.limit = value
+			found = true
+		}
+
+		if value, err := strconv.Atoi(header.Get(prefix + "-remaining")); err == nil {
+			*limit.remaining = value
+			found = true
+		}
+
+		if value, err := time.Parse(time.RFC3339, header.Get(prefix+"-reset")); err == nil {
+			*limit.reset = value
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return rateLimit
+}
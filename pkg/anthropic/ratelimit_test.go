@@ -0,0 +1,37 @@
+package anthropic
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	header := http.Header{}
+	header.Set("anthropic-ratelimit-requests-limit", "50")
+	header.Set("anthropic-ratelimit-requests-remaining", "49")
+	header.Set("anthropic-ratelimit-requests-reset", "2024-11-01T12:00:01Z")
+	header.Set("anthropic-ratelimit-input-tokens-limit", "40000")
+	header.Set("anthropic-ratelimit-input-tokens-remaining", "39000")
+	header.Set("anthropic-ratelimit-output-tokens-remaining", "not-a-number")
+
+	rateLimit := ParseRateLimit(header)
+	if rateLimit == nil {
+		t.Fatal("Expected a rate limit")
+	}
+
+	expected := RateLimit{
+		RequestsLimit:        50,
+		RequestsRemaining:    49,
+		RequestsReset:        time.Date(2024, 11, 1, 12, 0, 1, 0, time.UTC),
+		InputTokensLimit:     40000,
+		InputTokensRemaining: 39000,
+	}
+	if *rateLimit != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *rateLimit)
+	}
+
+	if ParseRateLimit(http.Header{}) != nil {
+		t.Error("Expected no rate limit without rate limit headers")
+	}
+}
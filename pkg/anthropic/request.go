@@ -1,30 +1,148 @@
 package anthropic
 
-// ContentBlock interface to allow for both TextContentBlock and ImageContentBlock
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ContentBlock interface to allow for all the content block types of requests and responses
 type ContentBlock interface {
 	// This method exists solely to enforce compile-time checking of the types that implement this interface.
 	isContentBlock()
 }
 
+// UnknownContentBlock preserves a content block of a type this package does not know about, so it
+// can still be sent back to the API unchanged.
+type UnknownContentBlock struct {
+	Type string
+	Raw  json.RawMessage
+}
+
+func (u UnknownContentBlock) isContentBlock() {}
+
+// MarshalJSON returns the block exactly as it was received.
+func (u UnknownContentBlock) MarshalJSON() ([]byte, error) {
+	if len(u.Raw) == 0 {
+		return json.Marshal(struct {
+			Type string `json:"type"`
+		}{Type: u.Type})
+	}
+	return u.Raw, nil
+}
+
+// unmarshalContentBlock decodes a single content block, dispatching on its type.
+func unmarshalContentBlock(data []byte) (ContentBlock, error) {
+	header := struct {
+		Type string `json:"type"`
+	}{}
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding content block: %w", err)
+	}
+
+	var block ContentBlock
+	switch header.Type {
+	case "text":
+		textBlock := TextContentBlock{}
+		err = json.Unmarshal(data, &textBlock)
+		block = textBlock
+	case "image":
+		imageBlock := ImageContentBlock{}
+		err = json.Unmarshal(data, &imageBlock)
+		block = imageBlock
+	case "document":
+		documentBlock := DocumentContentBlock{}
+		err = json.Unmarshal(data, &documentBlock)
+		block = documentBlock
+	case "tool_use":
+		toolUseBlock := ToolUseContentBlock{}
+		err = json.Unmarshal(data, &toolUseBlock)
+		block = toolUseBlock
+	case "tool_result":
+		toolResultBlock := ToolResultContentBlock{}
+		err = json.Unmarshal(data, &toolResultBlock)
+		block = toolResultBlock
+	case "thinking":
+		thinkingBlock := ThinkingContentBlock{}
+		err = json.Unmarshal(data, &thinkingBlock)
+		block = thinkingBlock
+	case "redacted_thinking":
+		redactedThinkingBlock := RedactedThinkingContentBlock{}
+		err = json.Unmarshal(data, &redactedThinkingBlock)
+		block = redactedThinkingBlock
+	default:
+		raw := make(json.RawMessage, len(data))
+		copy(raw, data)
+		block = UnknownContentBlock{Type: header.Type, Raw: raw}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s content block: %w", header.Type, err)
+	}
+
+	return block, nil
+}
+
+// unmarshalContent decodes content given either as an array of blocks or as a string, the
+// shorthand for a single text block.
+func unmarshalContent(data json.RawMessage) ([]ContentBlock, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		return []ContentBlock{NewTextContentBlock(text)}, nil
+	}
+
+	raw := []json.RawMessage{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding content: %w", err)
+	}
+
+	return unmarshalContentBlocks(raw)
+}
+
+func unmarshalContentBlocks(data []json.RawMessage) ([]ContentBlock, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	blocks := make([]ContentBlock, 0, len(data))
+	for _, raw := range data {
+		block, err := unmarshalContentBlock(raw)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
 // TextContentBlock represents a block of text content.
 type TextContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type         string        `json:"type"`
+	Text         string        `json:"text"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // optional
+	// Citations are set on response text supported by documents with citations enabled.
+	Citations []Citation `json:"citations,omitempty"`
 }
 
 func (t TextContentBlock) isContentBlock() {}
 
-// ImageSource represents the source of an image, supporting base64 encoding for now.
+// ImageSource represents the source of an image: base64 encoded data, or a URL.
 type ImageSource struct {
 	Type      string    `json:"type"`
-	MediaType MediaType `json:"media_type"`
-	Data      string    `json:"data"`
+	MediaType MediaType `json:"media_type,omitempty"`
+	Data      string    `json:"data,omitempty"`
+	URL       string    `json:"url,omitempty"`
 }
 
 // ImageContentBlock represents a block of image content.
 type ImageContentBlock struct {
-	Type   string      `json:"type"`
-	Source ImageSource `json:"source"`
+	Type         string        `json:"type"`
+	Source       ImageSource   `json:"source"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // optional
 }
 
 func (i ImageContentBlock) isContentBlock() {}
@@ -35,6 +153,23 @@ type MessagePartRequest struct {
 	Content []ContentBlock `json:"content"`
 }
 
+// UnmarshalJSON decodes the content of the message into typed content blocks, accepting the
+// string shorthand for a single text block as well.
+func (m *MessagePartRequest) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}{}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	m.Role = raw.Role
+	m.Content, err = unmarshalContent(raw.Content)
+	return err
+}
+
 // Helper functions to create text and image content blocks easily
 func NewTextContentBlock(text string) ContentBlock {
 	return TextContentBlock{
@@ -57,7 +192,7 @@ func NewImageContentBlock(mediaType MediaType, base64Data string) ContentBlock {
 	return ImageContentBlock{
 		Type: "image",
 		Source: ImageSource{
-			Type:      "base64",
+			Type:      ImageSourceTypeBase64,
 			MediaType: mediaType,
 			Data:      base64Data,
 		},
@@ -89,10 +224,46 @@ func NewToolUseContentBlock(id string, name string, input interface{}) ContentBl
 
 // ToolResultContentBlock represents a block of tool result content.
 type ToolResultContentBlock struct {
-	Type      string      `json:"type"`
-	ToolUseID string      `json:"tool_use_id"`
-	Content   interface{} `json:"content"`
-	IsError   bool        `json:"is_error,omitempty"`
+	Type         string        `json:"type"`
+	ToolUseID    string        `json:"tool_use_id"`
+	Content      interface{}   `json:"content"`
+	IsError      bool          `json:"is_error,omitempty"`
+	CacheControl *CacheControl `json:"cache_control,omitempty"` // optional
+}
+
+// UnmarshalJSON keeps string content as a string and decodes block content into typed content
+// blocks.
+func (t *ToolResultContentBlock) UnmarshalJSON(data []byte) error {
+	type alias ToolResultContentBlock
+	raw := struct {
+		*alias
+		Content json.RawMessage `json:"content"`
+	}{
+		alias: (*alias)(t),
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	t.Content = nil
+	if len(raw.Content) == 0 || string(raw.Content) == "null" {
+		return nil
+	}
+
+	var text string
+	if json.Unmarshal(raw.Content, &text) == nil {
+		t.Content = text
+		return nil
+	}
+
+	blocks, err := unmarshalContent(raw.Content)
+	if err != nil {
+		return err
+	}
+	t.Content = blocks
+	return nil
 }
 
 // NewToolResultContentBlock creates a new tool result content block with the given parameters.
@@ -117,7 +288,8 @@ type MessageRequest struct {
 	Tools             []Tool               `json:"tools,omitempty"`
 	Messages          []MessagePartRequest `json:"messages"`
 	MaxTokensToSample int                  `json:"max_tokens"`
-	SystemPrompt      string               `json:"system,omitempty"`         // optional
+	SystemPrompt      string               `json:"-"`                        // optional
+	SystemBlocks      []TextContentBlock   `json:"-"`                        // optional
 	Metadata          interface{}          `json:"metadata,omitempty"`       // optional
 	StopSequences     []string             `json:"stop_sequences,omitempty"` // optional
 	Stream            bool                 `json:"stream,omitempty"`         // optional
@@ -125,41 +297,197 @@ type MessageRequest struct {
 	ToolChoice        *ToolChoice          `json:"tool_choice,omitempty"`    // optional
 	TopK              int                  `json:"top_k,omitempty"`          // optional
 	TopP              float64              `json:"top_p,omitempty"`          // optional
+	Thinking          *ThinkingConfig      `json:"thinking,omitempty"`       // optional
+}
+
+// MarshalJSON sends the system prompt as a plain string, or as an array of text blocks when
+// SystemBlocks is set. In that case a non-empty SystemPrompt is sent as the first block.
+func (m MessageRequest) MarshalJSON() ([]byte, error) {
+	type alias MessageRequest
+	return json.Marshal(struct {
+		alias
+		System interface{} `json:"system,omitempty"`
+	}{
+		alias:  alias(m),
+		System: m.system(),
+	})
+}
+
+// UnmarshalJSON decodes a system prompt given as a string into SystemPrompt and one given as an
+// array of text blocks into SystemBlocks.
+func (m *MessageRequest) UnmarshalJSON(data []byte) error {
+	type alias MessageRequest
+	raw := struct {
+		*alias
+		System json.RawMessage `json:"system"`
+	}{
+		alias: (*alias)(m),
+	}
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	m.SystemPrompt = ""
+	m.SystemBlocks = nil
+	if len(raw.System) == 0 || string(raw.System) == "null" {
+		return nil
+	}
+
+	if json.Unmarshal(raw.System, &m.SystemPrompt) == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(raw.System, &m.SystemBlocks)
+	if err != nil {
+		return fmt.Errorf("error decoding system prompt: %w", err)
+	}
+	return nil
 }
 
+// system returns the value of the system field of the request, nil if there is no system prompt.
+func (m *MessageRequest) system() interface{} {
+	if len(m.SystemBlocks) == 0 {
+		if m.SystemPrompt == "" {
+			return nil
+		}
+		return m.SystemPrompt
+	}
+
+	if m.SystemPrompt == "" {
+		return m.SystemBlocks
+	}
+
+	blocks := make([]TextContentBlock, 0, len(m.SystemBlocks)+1)
+	blocks = append(blocks, TextContentBlock{Type: "text", Text: m.SystemPrompt})
+	return append(blocks, m.SystemBlocks...)
+}
+
+// InputSchemaProperty describes a value of a tool's input using the subset of JSON Schema
+// accepted by the Messages API. Only Type and Description are needed for simple properties.
 type InputSchemaProperty struct {
 	Type        string `json:"type"`
 	Description string `json:"description"`
+
+	// Optional fields, only present for enums, arrays, objects and numbers
+	Enum       []interface{}                  `json:"enum,omitempty"`
+	Items      *InputSchemaProperty           `json:"items,omitempty"`
+	Properties map[string]InputSchemaProperty `json:"properties,omitempty"`
+	Required   []string                       `json:"required,omitempty"`
+	Minimum    *float64                       `json:"minimum,omitempty"`
+	Maximum    *float64                       `json:"maximum,omitempty"`
+
+	// Optional fields, further constraints and composition
+	Const                interface{}           `json:"const,omitempty"`
+	Default              interface{}           `json:"default,omitempty"`
+	Format               string                `json:"format,omitempty"`
+	Pattern              string                `json:"pattern,omitempty"`
+	MinLength            *int                  `json:"minLength,omitempty"`
+	MaxLength            *int                  `json:"maxLength,omitempty"`
+	ExclusiveMinimum     *float64              `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64              `json:"exclusiveMaximum,omitempty"`
+	MinItems             *int                  `json:"minItems,omitempty"`
+	MaxItems             *int                  `json:"maxItems,omitempty"`
+	UniqueItems          bool                  `json:"uniqueItems,omitempty"`
+	AdditionalProperties interface{}           `json:"additionalProperties,omitempty"` // bool or *InputSchemaProperty
+	OneOf                []InputSchemaProperty `json:"oneOf,omitempty"`
+	AnyOf                []InputSchemaProperty `json:"anyOf,omitempty"`
+	AllOf                []InputSchemaProperty `json:"allOf,omitempty"`
+}
+
+// UnmarshalJSON decodes an additionalProperties schema into an *InputSchemaProperty.
+func (p *InputSchemaProperty) UnmarshalJSON(data []byte) error {
+	type alias InputSchemaProperty
+	raw := struct {
+		*alias
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}{
+		alias: (*alias)(p),
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	p.AdditionalProperties, err = unmarshalAdditionalProperties(raw.AdditionalProperties)
+	return err
+}
+
+// MarshalJSON omits the type of properties that do not set one, such as those only defined
+// through oneOf, anyOf or allOf.
+func (p InputSchemaProperty) MarshalJSON() ([]byte, error) {
+	type alias InputSchemaProperty
+	if p.Type != "" {
+		return json.Marshal(alias(p))
+	}
+
+	return json.Marshal(struct {
+		Type string `json:"type,omitempty"`
+		alias
+	}{
+		alias: alias(p),
+	})
 }
 
 type InputSchema struct {
 	Type       string                         `json:"type"`
 	Properties map[string]InputSchemaProperty `json:"properties"`
 	Required   []string                       `json:"required,omitempty"`
+
+	// Optional fields
+	Description          string      `json:"description,omitempty"`
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"` // bool or *InputSchemaProperty
 }
 
-type Tool struct {
-	Name            string      `json:"name"`
-	Description     string      `json:"description,omitempty"`
-	InputSchema     InputSchema `json:"input_schema,omitempty"`
-	DisplayWidthPx  int         `json:"display_width_px,omitempty"`
-	DisplayHeightPx int         `json:"display_height_px,omitempty"`
-	DisplayNumber   int         `json:"display_number,omitempty"`
+// UnmarshalJSON decodes an additionalProperties schema into an *InputSchemaProperty.
+func (s *InputSchema) UnmarshalJSON(data []byte) error {
+	type alias InputSchema
+	raw := struct {
+		*alias
+		AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	}{
+		alias: (*alias)(s),
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	s.AdditionalProperties, err = unmarshalAdditionalProperties(raw.AdditionalProperties)
+	return err
 }
 
-const (
-	ToolTypeComputer   = "computer_20241022"
-	ToolTypeTextEditor = "text_editor_20241022"
-	ToolTypeBash       = "bash_20241022"
-)
+func unmarshalAdditionalProperties(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
 
-// Update helper functions to match the correct schema
-func NewComputerTool(width, height, displayNum int) Tool {
-	return Tool{
-		DisplayWidthPx:  width,
-		DisplayHeightPx: height,
-		DisplayNumber:   displayNum,
+	var allowed bool
+	if json.Unmarshal(data, &allowed) == nil {
+		return allowed, nil
 	}
+
+	property := &InputSchemaProperty{}
+	err := json.Unmarshal(data, property)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding additionalProperties: %w", err)
+	}
+	return property, nil
+}
+
+type Tool struct {
+	// Type is the versioned type of Anthropic-defined tools, e.g. ToolTypeComputer, empty for
+	// custom tools.
+	Type            string        `json:"type,omitempty"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description,omitempty"`
+	InputSchema     InputSchema   `json:"input_schema,omitempty"`
+	DisplayWidthPx  int           `json:"display_width_px,omitempty"`
+	DisplayHeightPx int           `json:"display_height_px,omitempty"`
+	DisplayNumber   int           `json:"display_number,omitempty"`
+	CacheControl    *CacheControl `json:"cache_control,omitempty"` // optional
 }
 
 // CountImageContent counts the number of ImageContentBlock in the MessageRequest.
@@ -0,0 +1,225 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMessageRequestJSONRoundTrip(t *testing.T) {
+	one := 1.0
+	request := NewMessageRequest(
+		WithMessageModel(Claude35Sonnet),
+		WithMessageMaxTokens(1024),
+		WithSystemPrompt("You are a weather bot."),
+		WithToolChoice("auto", ""),
+	)
+	request.Tools = []Tool{{
+		Name:        "get_weather",
+		Description: "Get the weather",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]InputSchemaProperty{
+				"city": {Type: "string", Description: "city to get the weather for"},
+				"days": {Type: "integer", Description: "number of days", Minimum: &one},
+				"extra": {
+					Type:                 "object",
+					AdditionalProperties: &InputSchemaProperty{Type: "string", Description: "note"},
+				},
+			},
+			Required:             []string{"city"},
+			AdditionalProperties: false,
+		},
+	}}
+	request.AddUserMessage(
+		NewTextContentBlock("What's the weather in this picture's city?"),
+		NewImageContentBlock(MediaTypePNG, "iVBORw0KGgo="),
+	)
+	request.AddAssistantMessage(
+		NewTextContentBlock("Let me check."),
+		ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{"city": "Charleston"}},
+	)
+	request.AddUserMessage(
+		NewToolResultContentBlock("toolu_01", "52f", false),
+		NewToolResultContentBlock("toolu_02", []ContentBlock{NewTextContentBlock("not available")}, true),
+	)
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded := &MessageRequest{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, request) {
+		t.Errorf("round trip mismatch\ngot:  %#v\nwant: %#v", decoded, request)
+	}
+
+	again, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(again) != string(data) {
+		t.Errorf("re-encoded json differs\ngot:  %s\nwant: %s", again, data)
+	}
+}
+
+func TestMessagePartRequestUnmarshalContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected MessagePartRequest
+	}{
+		{
+			name: "string shorthand",
+			data: `{"role": "user", "content": "Hello, Claude"}`,
+			expected: MessagePartRequest{
+				Role:    "user",
+				Content: []ContentBlock{NewTextContentBlock("Hello, Claude")},
+			},
+		},
+		{
+			name: "text",
+			data: `{"role": "user", "content": [{"type": "text", "text": "Hello"}]}`,
+			expected: MessagePartRequest{
+				Role:    "user",
+				Content: []ContentBlock{NewTextContentBlock("Hello")},
+			},
+		},
+		{
+			name: "image",
+			data: `{"role": "user", "content": [{"type": "image", "source": {"type": "base64", "media_type": "image/jpeg", "data": "abc"}}]}`,
+			expected: MessagePartRequest{
+				Role:    "user",
+				Content: []ContentBlock{NewImageContentBlock(MediaTypeJPEG, "abc")},
+			},
+		},
+		{
+			name: "tool_use",
+			data: `{"role": "assistant", "content": [{"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {"city": "Charleston"}}]}`,
+			expected: MessagePartRequest{
+				Role: "assistant",
+				Content: []ContentBlock{
+					ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{"city": "Charleston"}},
+				},
+			},
+		},
+		{
+			name: "tool_result with string content",
+			data: `{"role": "user", "content": [{"type": "tool_result", "tool_use_id": "toolu_01", "content": "52f"}]}`,
+			expected: MessagePartRequest{
+				Role:    "user",
+				Content: []ContentBlock{NewToolResultContentBlock("toolu_01", "52f", false)},
+			},
+		},
+		{
+			name: "tool_result with block content",
+			data: `{"role": "user", "content": [{"type": "tool_result", "tool_use_id": "toolu_01", "content": [{"type": "text", "text": "failed"}], "is_error": true}]}`,
+			expected: MessagePartRequest{
+				Role: "user",
+				Content: []ContentBlock{
+					NewToolResultContentBlock("toolu_01", []ContentBlock{NewTextContentBlock("failed")}, true),
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		message := MessagePartRequest{}
+		err := json.Unmarshal([]byte(test.data), &message)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(message, test.expected) {
+			t.Errorf("%s: unexpected message\ngot:  %#v\nwant: %#v", test.name, message, test.expected)
+		}
+	}
+}
+
+func TestMessagePartRequestUnmarshalInvalidContent(t *testing.T) {
+	message := MessagePartRequest{}
+	err := json.Unmarshal([]byte(`{"role": "user", "content": 42}`), &message)
+	if err == nil {
+		t.Error("expected error for numeric content, got nil")
+	}
+}
+
+func TestMessageRequestSystem(t *testing.T) {
+	cached := NewEphemeralCacheControl()
+
+	tests := []struct {
+		name     string
+		request  *MessageRequest
+		expected string
+	}{
+		{
+			name:     "no system prompt",
+			request:  &MessageRequest{},
+			expected: ``,
+		},
+		{
+			name:     "string",
+			request:  &MessageRequest{SystemPrompt: "Be brief."},
+			expected: `"Be brief."`,
+		},
+		{
+			name: "blocks",
+			request: &MessageRequest{SystemBlocks: []TextContentBlock{
+				{Type: "text", Text: "Static instructions.", CacheControl: cached},
+				{Type: "text", Text: "Today is Monday."},
+			}},
+			expected: `[{"type":"text","text":"Static instructions.","cache_control":{"type":"ephemeral"}},{"type":"text","text":"Today is Monday."}]`,
+		},
+		{
+			name: "string and blocks",
+			request: &MessageRequest{
+				SystemPrompt: "Be brief.",
+				SystemBlocks: []TextContentBlock{{Type: "text", Text: "Today is Monday."}},
+			},
+			expected: `[{"type":"text","text":"Be brief."},{"type":"text","text":"Today is Monday."}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			fields := map[string]json.RawMessage{}
+			err = json.Unmarshal(data, &fields)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(fields["system"]) != test.expected {
+				t.Errorf("expected system %s, got %s", test.expected, fields["system"])
+			}
+		})
+	}
+}
+
+func TestMessageRequestUnmarshalSystem(t *testing.T) {
+	request := &MessageRequest{}
+	err := json.Unmarshal([]byte(`{"model":"claude-3-5-sonnet-latest","messages":[],"max_tokens":10,"system":[{"type":"text","text":"Be brief.","cache_control":{"type":"ephemeral"}}]}`), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []TextContentBlock{{Type: "text", Text: "Be brief.", CacheControl: NewEphemeralCacheControl()}}
+	if request.SystemPrompt != "" || !reflect.DeepEqual(request.SystemBlocks, expected) {
+		t.Errorf("unexpected system prompt %q, blocks %#v", request.SystemPrompt, request.SystemBlocks)
+	}
+
+	if request.Model != Claude35Sonnet || request.MaxTokensToSample != 10 {
+		t.Errorf("unexpected request %#v", request)
+	}
+}
@@ -1,5 +1,10 @@
 package anthropic
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // CompletionResponse is the response from the Anthropic API for a completion request.
 type CompletionResponse struct {
 	Completion string `json:"completion"`
@@ -16,49 +21,178 @@ type StreamResponse struct {
 	LogID      string `json:"log_id"`
 }
 
-// MessageResponse is a subset of the response from the Anthropic API for a message response.
-type MessagePartResponse struct {
+// MessageResponse is the response from the Anthropic API for a message response.
+type MessageResponse struct {
+	ID           string         `json:"id"`
+	Type         string         `json:"type"`
+	Model        string         `json:"model"`
+	Role         string         `json:"role"`
+	Content      []ContentBlock `json:"content"`
+	StopReason   string         `json:"stop_reason"`
+	Stop         string         `json:"stop"`
+	StopSequence string         `json:"stop_sequence"`
+	Usage        MessageUsage   `json:"usage"`
+	// RateLimit is parsed from the response headers, nil when they weren't available.
+	RateLimit *RateLimit `json:"-"`
+	// RequestID identifies the request, taken from the response headers.
+	RequestID string `json:"-"`
+}
+
+// UnmarshalJSON decodes the content of the response into typed content blocks.
+func (r *MessageResponse) UnmarshalJSON(data []byte) error {
+	type alias MessageResponse
+	raw := struct {
+		*alias
+		Content []json.RawMessage `json:"content"`
+	}{
+		alias: (*alias)(r),
+	}
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	r.Content, err = unmarshalContentBlocks(raw.Content)
+	return err
+}
+
+// Text returns the concatenated text of the response's text blocks.
+func (r *MessageResponse) Text() string {
+	text := strings.Builder{}
+	for _, block := range r.Content {
+		if textBlock, ok := block.(TextContentBlock); ok {
+			text.WriteString(textBlock.Text)
+		}
+	}
+	return text.String()
+}
+
+// Thinking returns the concatenated reasoning of the response's thinking blocks.
+func (r *MessageResponse) Thinking() string {
+	thinking := strings.Builder{}
+	for _, block := range r.Content {
+		if thinkingBlock, ok := block.(ThinkingContentBlock); ok {
+			thinking.WriteString(thinkingBlock.Thinking)
+		}
+	}
+	return thinking.String()
+}
+
+// ToolUses returns the tool_use blocks of the response, in the order the model requested them.
+func (r *MessageResponse) ToolUses() []ToolUseContentBlock {
+	toolUses := []ToolUseContentBlock{}
+	for _, block := range r.Content {
+		if toolUse, ok := block.(ToolUseContentBlock); ok {
+			toolUses = append(toolUses, toolUse)
+		}
+	}
+	return toolUses
+}
+
+type MessageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type MessageStreamResponse struct {
+	Type         string                     `json:"type"`
+	Message      *MessageResponse           `json:"message,omitempty"`
+	Index        int                        `json:"index"`
+	ContentBlock *MessageStreamContentBlock `json:"content_block,omitempty"`
+	Delta        MessageStreamDelta         `json:"delta"`
+	Usage        MessageStreamUsage         `json:"usage"`
+	// RateLimit is parsed from the response headers and set on the message_start event only.
+	RateLimit *RateLimit `json:"-"`
+	// RequestID identifies the request, taken from the response headers and set on the
+	// message_start event only.
+	RequestID string `json:"-"`
+}
+
+// MessageStreamContentBlock is the content block carried by content_block_start events and,
+// once fully assembled by a MessageEventParser, by content_block_stop events.
+type MessageStreamContentBlock struct {
 	Type string `json:"type"`
-	Text string `json:"text"`
+	Text string `json:"text,omitempty"`
 
-	// Optional fields, only present for tools responses
+	// Optional fields, only present for tool_use blocks
 	ID    string                 `json:"id,omitempty"`
 	Name  string                 `json:"name,omitempty"`
 	Input map[string]interface{} `json:"input,omitempty"`
+
+	// Optional fields, only present for thinking and redacted_thinking blocks
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
+
+	// Optional field, only present for text blocks citing documents
+	Citations []Citation `json:"citations,omitempty"`
 }
 
-// MessageResponse is the response from the Anthropic API for a message response.
-type MessageResponse struct {
-	ID           string                `json:"id"`
-	Type         string                `json:"type"`
-	Model        string                `json:"model"`
-	Role         string                `json:"role"`
-	Content      []MessagePartResponse `json:"content"`
-	StopReason   string                `json:"stop_reason"`
-	Stop         string                `json:"stop"`
-	StopSequence string                `json:"stop_sequence"`
-	Usage        MessageUsage          `json:"usage"`
+// addCitation appends a citation received in a citations_delta to the block.
+func (b *MessageStreamContentBlock) addCitation(citation *Citation) {
+	if citation != nil {
+		b.Citations = append(b.Citations, *citation)
+	}
 }
 
-type MessageUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+// streamedText returns the field of the block assembled from text or thinking deltas.
+func (b *MessageStreamContentBlock) streamedText() string {
+	if b.Type == "thinking" {
+		return b.Thinking
+	}
+	return b.Text
 }
 
-type MessageStreamResponse struct {
-	Type  string             `json:"type"`
-	Delta MessageStreamDelta `json:"delta"`
-	Usage MessageStreamUsage `json:"usage"`
+// setStreamedText sets the field of the block assembled from text or thinking deltas.
+func (b *MessageStreamContentBlock) setStreamedText(text string) {
+	if b.Type == "thinking" {
+		b.Thinking = text
+		return
+	}
+	b.Text = text
+}
+
+// contentBlock converts the streamed block to the typed content block Message would have returned.
+func (b *MessageStreamContentBlock) contentBlock() ContentBlock {
+	switch b.Type {
+	case "text":
+		return TextContentBlock{Type: b.Type, Text: b.Text, Citations: b.Citations}
+	case "tool_use":
+		input := b.Input
+		if input == nil {
+			input = map[string]interface{}{}
+		}
+		return ToolUseContentBlock{Type: b.Type, ID: b.ID, Name: b.Name, Input: input}
+	case "thinking":
+		return ThinkingContentBlock{Type: b.Type, Thinking: b.Thinking, Signature: b.Signature}
+	case "redacted_thinking":
+		return RedactedThinkingContentBlock{Type: b.Type, Data: b.Data}
+	}
+
+	raw, err := json.Marshal(b)
+	if err != nil {
+		return UnknownContentBlock{Type: b.Type}
+	}
+	return UnknownContentBlock{Type: b.Type, Raw: raw}
 }
 
 type MessageStreamDelta struct {
-	Type         string `json:"type"`
-	Text         string `json:"text"`
-	StopReason   string `json:"stop_reason"`
-	StopSequence string `json:"stop_sequence"`
+	Type         string    `json:"type"`
+	Text         string    `json:"text"`
+	PartialJSON  string    `json:"partial_json"`
+	Thinking     string    `json:"thinking,omitempty"`
+	Signature    string    `json:"signature,omitempty"`
+	Citation     *Citation `json:"citation,omitempty"`
+	StopReason   string    `json:"stop_reason"`
+	StopSequence string    `json:"stop_sequence"`
 }
 
 type MessageStreamUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
@@ -0,0 +1,72 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMessageResponseUnmarshalContentBlocks(t *testing.T) {
+	data := `{
+		"id": "msg_01",
+		"type": "message",
+		"role": "assistant",
+		"model": "claude-3-5-sonnet-20241022",
+		"content": [
+			{"type": "text", "text": "Let me check."},
+			{"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {"city": "Charleston"}},
+			{"type": "future_block", "payload": {"answer": 42}}
+		],
+		"stop_reason": "tool_use",
+		"stop_sequence": null,
+		"usage": {"input_tokens": 10, "output_tokens": 5}
+	}`
+
+	response := &MessageResponse{}
+	err := json.Unmarshal([]byte(data), response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.ID != "msg_01" || response.StopReason != "tool_use" || response.Usage.OutputTokens != 5 {
+		t.Errorf("unexpected response metadata: %+v", response)
+	}
+
+	expected := []ContentBlock{
+		TextContentBlock{Type: "text", Text: "Let me check."},
+		ToolUseContentBlock{Type: "tool_use", ID: "toolu_01", Name: "get_weather", Input: map[string]interface{}{"city": "Charleston"}},
+		UnknownContentBlock{Type: "future_block", Raw: json.RawMessage(`{"type": "future_block", "payload": {"answer": 42}}`)},
+	}
+
+	if !reflect.DeepEqual(response.Content, expected) {
+		t.Errorf("unexpected content\ngot:  %#v\nwant: %#v", response.Content, expected)
+	}
+
+	if response.Text() != "Let me check." {
+		t.Errorf("unexpected text %q", response.Text())
+	}
+
+	if toolUses := response.ToolUses(); len(toolUses) != 1 || toolUses[0].ID != "toolu_01" {
+		t.Errorf("unexpected tool uses %+v", toolUses)
+	}
+}
+
+func TestMessageResponseContentRoundTrip(t *testing.T) {
+	response := &MessageResponse{}
+	err := json.Unmarshal([]byte(`{"content": [{"type": "tool_use", "id": "toolu_01", "name": "get_weather", "input": {"city": "Charleston"}}, {"type": "future_block", "payload": 1}]}`), response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	request := NewMessageRequest().AddAssistantMessage(response.Content...)
+
+	data, err := json.Marshal(request.Messages)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `[{"role":"assistant","content":[{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{"city":"Charleston"}},{"type":"future_block","payload":1}]}]`
+	if string(data) != expected {
+		t.Errorf("unexpected json\ngot:  %s\nwant: %s", data, expected)
+	}
+}
@@ -0,0 +1,173 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRetryMaxAttempts = 3
+	DefaultRetryBaseDelay   = 500 * time.Millisecond
+	DefaultRetryMaxDelay    = 8 * time.Second
+)
+
+// RetryPolicy configures how the clients retry failed requests. Zero fields fall back to their
+// defaults; a nil *RetryPolicy disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one (defaults to
+	// DefaultRetryMaxAttempts).
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled before every following retry (defaults
+	// to DefaultRetryBaseDelay).
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay (defaults to DefaultRetryMaxDelay).
+	MaxDelay time.Duration
+	// Jitter is the fraction of the backoff delay, between 0 and 1, randomly taken off each delay so
+	// concurrent clients don't retry in lockstep.
+	Jitter float64
+	// Retryable reports whether a failed attempt should be retried (defaults to DefaultRetryable).
+	// statusCode is 0 when no HTTP response was received.
+	Retryable func(statusCode int, err error) bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with the default settings and some jitter.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultRetryMaxAttempts,
+		BaseDelay:   DefaultRetryBaseDelay,
+		MaxDelay:    DefaultRetryMaxDelay,
+		Jitter:      0.25,
+	}
+}
+
+// DefaultRetryable retries request timeouts, conflicts, rate limits, server errors including 529
+// overloaded, and network errors.
+func DefaultRetryable(statusCode int, err error) bool {
+	switch {
+	case statusCode == http.StatusRequestTimeout,
+		statusCode == http.StatusConflict,
+		statusCode == http.StatusTooManyRequests,
+		statusCode >= http.StatusInternalServerError:
+		return true
+	case statusCode != 0:
+		return false
+	}
+
+	// errors received without a status code, e.g. on a stream, are matched by their type
+	if errors.Is(err, ErrAnthropicOverloaded) || errors.Is(err, ErrAnthropicRateLimit) || errors.Is(err, ErrAnthropicInternalServer) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// ShouldRetry reports whether the failed attempt, numbered from 1, should be retried. Errors caused
+// by the context being cancelled or timing out are never retried.
+func (p *RetryPolicy) ShouldRetry(attempt int, statusCode int, err error) bool {
+	if p == nil || attempt >= p.maxAttempts() {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = DefaultRetryable
+	}
+	return retryable(statusCode, err)
+}
+
+// Delay returns how long to wait after the failed attempt, numbered from 1. The retry-after-ms and
+// retry-after headers of the failed response take precedence over the exponential backoff.
+func (p *RetryPolicy) Delay(attempt int, header http.Header) time.Duration {
+	if delay, ok := retryAfter(header); ok {
+		return delay
+	}
+
+	baseDelay := p.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+
+	delay := float64(baseDelay) * math.Pow(2, float64(attempt-1))
+	if delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+
+	jitter := math.Min(math.Max(p.Jitter, 0), 1)
+	delay -= delay * jitter * rand.Float64()
+
+	return time.Duration(delay)
+}
+
+// Wait blocks for the Delay of the failed attempt, returning early with the context's error if it
+// is done first.
+func (p *RetryPolicy) Wait(ctx context.Context, attempt int, header http.Header) error {
+	timer := time.NewTimer(p.Delay(attempt, header))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return p.MaxAttempts
+}
+
+// retryAfter parses the delay requested by the server, given in milliseconds by retry-after-ms or
+// in seconds or as an HTTP date by retry-after.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	if value := header.Get("retry-after-ms"); value != "" {
+		milliseconds, err := strconv.ParseFloat(value, 64)
+		if err == nil && milliseconds >= 0 {
+			return time.Duration(milliseconds * float64(time.Millisecond)), true
+		}
+	}
+
+	value := header.Get("retry-after")
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+
+	date, err := http.ParseTime(value)
+	if err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		name       string
+		policy     *RetryPolicy
+		attempt    int
+		statusCode int
+		err        error
+		expected   bool
+	}{
+		{name: "nil policy", policy: nil, attempt: 1, statusCode: 529, expected: false},
+		{name: "rate limit", policy: policy, attempt: 1, statusCode: 429, expected: true},
+		{name: "overloaded", policy: policy, attempt: 2, statusCode: 529, expected: true},
+		{name: "server error", policy: policy, attempt: 1, statusCode: 500, expected: true},
+		{name: "request timeout", policy: policy, attempt: 1, statusCode: 408, expected: true},
+		{name: "conflict", policy: policy, attempt: 1, statusCode: 409, expected: true},
+		{name: "bad request", policy: policy, attempt: 1, statusCode: 400, expected: false},
+		{name: "attempts exhausted", policy: policy, attempt: 3, statusCode: 529, expected: false},
+		{name: "context cancelled", policy: policy, attempt: 1, err: context.Canceled, expected: false},
+		{name: "not a network error", policy: policy, attempt: 1, err: errors.New("boom"), expected: false},
+		{name: "streamed overloaded error", policy: policy, attempt: 1, err: streamedError(t, "overloaded_error"), expected: true},
+		{name: "streamed invalid request", policy: policy, attempt: 1, err: streamedError(t, "invalid_request_error"), expected: false},
+		{
+			name: "custom retryable",
+			policy: &RetryPolicy{Retryable: func(statusCode int, err error) bool {
+				return statusCode == 400
+			}},
+			attempt:    1,
+			statusCode: 400,
+			expected:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := test.policy.ShouldRetry(test.attempt, test.statusCode, test.err)
+			if actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+// streamedError returns the error of an error event of errorType received on a stream.
+func streamedError(t *testing.T, errorType string) error {
+	_, err := NewMessageEventParser().Parse(MessageEventTypeError, `{"type": "error", "error": {"type": "`+errorType+`", "message": "failed"}}`)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	return fmt.Errorf("error processing message stream: %w", err)
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		name     string
+		attempt  int
+		header   http.Header
+		expected time.Duration
+	}{
+		{name: "first retry", attempt: 1, expected: time.Second},
+		{name: "backoff", attempt: 3, expected: 4 * time.Second},
+		{name: "capped", attempt: 10, expected: 5 * time.Second},
+		{name: "retry-after", attempt: 1, header: http.Header{"Retry-After": {"7"}}, expected: 7 * time.Second},
+		{name: "retry-after-ms", attempt: 1, header: http.Header{"Retry-After-Ms": {"250"}, "Retry-After": {"7"}}, expected: 250 * time.Millisecond},
+		{name: "invalid retry-after", attempt: 2, header: http.Header{"Retry-After": {"soon"}}, expected: 2 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := policy.Delay(test.attempt, test.header)
+			if actual != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyJitter(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(1, nil)
+		if delay < 500*time.Millisecond || delay > time.Second {
+			t.Fatalf("delay %v out of the jitter range", delay)
+		}
+	}
+}
+
+func TestRetryPolicyWaitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	policy := &RetryPolicy{BaseDelay: time.Hour}
+	err := policy.Wait(ctx, 1, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
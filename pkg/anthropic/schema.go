@@ -0,0 +1,386 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GenerateInputSchema builds the InputSchema of a tool from the Go struct v (or a pointer to one).
+//
+// Properties are named after the field's json tag. Pointer fields and fields tagged omitempty are
+// optional, every other field is required. Further constraints are read from the jsonschema tag:
+//
+//	type WeatherRequest struct {
+//		City string  `json:"city" jsonschema:"description=city to get the weather for"`
+//		Unit *string `json:"unit" jsonschema:"enum=celsius,enum=fahrenheit"`
+//		Days int     `json:"days,omitempty" jsonschema:"required,minimum=1,maximum=7"`
+//	}
+//
+// Supported keys are required, description, enum, default, format, pattern, minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength, minItems and maxItems. A description
+// may contain commas as long as it is the last key of the tag.
+func GenerateInputSchema(v interface{}) (InputSchema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return InputSchema{}, fmt.Errorf("input schema can only be generated from a struct, got %v", t)
+	}
+
+	property, err := schemaForType(t, map[reflect.Type]bool{})
+	if err != nil {
+		return InputSchema{}, err
+	}
+
+	return InputSchema{
+		Type:       property.Type,
+		Properties: property.Properties,
+		Required:   property.Required,
+	}, nil
+}
+
+// NewToolFromStruct creates a Tool whose InputSchema is generated from the Go struct v, see
+// GenerateInputSchema.
+func NewToolFromStruct(name, description string, v interface{}) (Tool, error) {
+	schema, err := GenerateInputSchema(v)
+	if err != nil {
+		return Tool{}, err
+	}
+
+	return Tool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+	}, nil
+}
+
+// DecodeToolInput decodes the input of a tool_use block into v, typically the same struct the
+// tool's InputSchema was generated from.
+func DecodeToolInput(input interface{}, v interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("error marshalling tool input: %w", err)
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return fmt.Errorf("error decoding tool input: %w", err)
+	}
+
+	return nil
+}
+
+// schemaForType builds the schema of t; visiting tracks the structs being built so recursive
+// types are reported instead of overflowing the stack.
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) (InputSchemaProperty, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaForType(t.Elem(), visiting)
+	case reflect.String:
+		return InputSchemaProperty{Type: "string"}, nil
+	case reflect.Bool:
+		return InputSchemaProperty{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return InputSchemaProperty{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return InputSchemaProperty{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings
+			return InputSchemaProperty{Type: "string"}, nil
+		}
+
+		items, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return InputSchemaProperty{}, err
+		}
+		return InputSchemaProperty{Type: "array", Items: &items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return InputSchemaProperty{}, fmt.Errorf("unsupported map key type %v, only string keys are supported", t.Key())
+		}
+
+		if t.Elem().Kind() == reflect.Interface {
+			return InputSchemaProperty{Type: "object"}, nil
+		}
+
+		values, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return InputSchemaProperty{}, err
+		}
+		return InputSchemaProperty{Type: "object", AdditionalProperties: &values}, nil
+	case reflect.Struct:
+		if t == timeType {
+			return InputSchemaProperty{Type: "string", Format: "date-time"}, nil
+		}
+
+		if visiting[t] {
+			return InputSchemaProperty{}, fmt.Errorf("recursive type %v is not supported", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		property := InputSchemaProperty{
+			Type:       "object",
+			Properties: map[string]InputSchemaProperty{},
+		}
+		err := addStructFields(&property, t, visiting)
+		if err != nil {
+			return InputSchemaProperty{}, err
+		}
+		return property, nil
+	}
+
+	return InputSchemaProperty{}, fmt.Errorf("unsupported type %v", t)
+}
+
+// addStructFields adds the fields of the struct t to the object schema, flattening embedded
+// structs the same way encoding/json does.
+func addStructFields(object *InputSchemaProperty, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		if field.Anonymous && fieldType.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			if visiting[fieldType] {
+				return fmt.Errorf("recursive type %v is not supported", fieldType)
+			}
+			visiting[fieldType] = true
+			err := addStructFields(object, fieldType, visiting)
+			delete(visiting, fieldType)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		property, err := schemaForType(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		tag, err := parseSchemaTag(field.Tag.Get("jsonschema"))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		err = tag.apply(&property)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		object.Properties[name] = property
+
+		optional := omitEmpty || field.Type.Kind() == reflect.Pointer
+		if tag.required || !optional {
+			object.Required = append(object.Required, name)
+		}
+	}
+
+	return nil
+}
+
+// jsonFieldName returns the name encoding/json uses for the field.
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, true
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+// schemaTag holds the constraints parsed from a jsonschema struct tag.
+type schemaTag struct {
+	required    bool
+	description string
+	enum        []string
+	defaultTo   *string
+	format      string
+	pattern     string
+	numbers     map[string]float64
+	lengths     map[string]int
+}
+
+func parseSchemaTag(tag string) (schemaTag, error) {
+	parsed := schemaTag{
+		numbers: map[string]float64{},
+		lengths: map[string]int{},
+	}
+	if tag == "" {
+		return parsed, nil
+	}
+
+	lastKey := ""
+	for _, part := range strings.Split(tag, ",") {
+		key, value, hasValue := strings.Cut(part, "=")
+
+		switch {
+		case key == "required" && !hasValue:
+			parsed.required = true
+		case key == "description" && hasValue:
+			parsed.description = value
+		case key == "enum" && hasValue:
+			parsed.enum = append(parsed.enum, value)
+		case key == "default" && hasValue:
+			parsed.defaultTo = &value
+		case key == "format" && hasValue:
+			parsed.format = value
+		case key == "pattern" && hasValue:
+			parsed.pattern = value
+		case (key == "minimum" || key == "maximum" || key == "exclusiveMinimum" || key == "exclusiveMaximum") && hasValue:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return parsed, fmt.Errorf("invalid %s %q: %w", key, value, err)
+			}
+			parsed.numbers[key] = number
+		case (key == "minLength" || key == "maxLength" || key == "minItems" || key == "maxItems") && hasValue:
+			length, err := strconv.Atoi(value)
+			if err != nil {
+				return parsed, fmt.Errorf("invalid %s %q: %w", key, value, err)
+			}
+			parsed.lengths[key] = length
+		case lastKey == "description":
+			// the description itself contained a comma
+			parsed.description += "," + part
+			continue
+		default:
+			return parsed, fmt.Errorf("unknown jsonschema tag option %q", part)
+		}
+
+		lastKey = key
+	}
+
+	return parsed, nil
+}
+
+// apply sets the parsed constraints on the schema of a field. Enums of array fields constrain
+// their items.
+func (tag schemaTag) apply(property *InputSchemaProperty) error {
+	property.Description = tag.description
+	if tag.format != "" {
+		property.Format = tag.format
+	}
+	property.Pattern = tag.pattern
+
+	for key, number := range tag.numbers {
+		number := number
+		switch key {
+		case "minimum":
+			property.Minimum = &number
+		case "maximum":
+			property.Maximum = &number
+		case "exclusiveMinimum":
+			property.ExclusiveMinimum = &number
+		case "exclusiveMaximum":
+			property.ExclusiveMaximum = &number
+		}
+	}
+
+	for key, length := range tag.lengths {
+		length := length
+		switch key {
+		case "minLength":
+			property.MinLength = &length
+		case "maxLength":
+			property.MaxLength = &length
+		case "minItems":
+			property.MinItems = &length
+		case "maxItems":
+			property.MaxItems = &length
+		}
+	}
+
+	if tag.defaultTo != nil {
+		converted, err := convertTagValue(*tag.defaultTo, property.Type)
+		if err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+		property.Default = converted
+	}
+
+	if len(tag.enum) == 0 {
+		return nil
+	}
+
+	target := property
+	if property.Type == "array" {
+		target = property.Items
+	}
+
+	for _, value := range tag.enum {
+		converted, err := convertTagValue(value, target.Type)
+		if err != nil {
+			return fmt.Errorf("invalid enum: %w", err)
+		}
+		target.Enum = append(target.Enum, converted)
+	}
+
+	return nil
+}
+
+// convertTagValue converts a value written in a struct tag to the JSON type of the schema.
+func convertTagValue(value string, schemaType string) (interface{}, error) {
+	switch schemaType {
+	case "string":
+		return value, nil
+	case "integer":
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer value %q: %w", value, err)
+		}
+		return number, nil
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number value %q: %w", value, err)
+		}
+		return number, nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean value %q: %w", value, err)
+		}
+		return boolean, nil
+	}
+
+	return nil, fmt.Errorf("values are not supported for %s schemas", schemaType)
+}
@@ -0,0 +1,227 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city" jsonschema:"description=city name, e.g. Charleston"`
+}
+
+type schemaTestBase struct {
+	ID string `json:"id" jsonschema:"description=request identifier"`
+}
+
+type schemaTestRequest struct {
+	schemaTestBase
+	Unit      string              `json:"unit" jsonschema:"enum=celsius,enum=fahrenheit,description=temperature unit"`
+	Days      int                 `json:"days,omitempty" jsonschema:"required,minimum=1,maximum=7"`
+	Verbose   *bool               `json:"verbose"`
+	Tags      []string            `json:"tags,omitempty" jsonschema:"enum=rain,enum=sun"`
+	Addresses []schemaTestAddress `json:"addresses"`
+	Home      *schemaTestAddress  `json:"home,omitempty"`
+	Levels    []int               `json:"levels,omitempty" jsonschema:"enum=1,enum=2"`
+	Extra     map[string]string   `json:"extra,omitempty"`
+	Ignored   string              `json:"-"`
+	internal  string
+}
+
+func TestGenerateInputSchema(t *testing.T) {
+	schema, err := GenerateInputSchema(&schemaTestRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	one, seven := 1.0, 7.0
+	address := InputSchemaProperty{
+		Type: "object",
+		Properties: map[string]InputSchemaProperty{
+			"street": {Type: "string"},
+			"city":   {Type: "string", Description: "city name, e.g. Charleston"},
+		},
+		Required: []string{"street", "city"},
+	}
+
+	expected := InputSchema{
+		Type: "object",
+		Properties: map[string]InputSchemaProperty{
+			"id":        {Type: "string", Description: "request identifier"},
+			"unit":      {Type: "string", Description: "temperature unit", Enum: []interface{}{"celsius", "fahrenheit"}},
+			"days":      {Type: "integer", Minimum: &one, Maximum: &seven},
+			"verbose":   {Type: "boolean"},
+			"tags":      {Type: "array", Items: &InputSchemaProperty{Type: "string", Enum: []interface{}{"rain", "sun"}}},
+			"addresses": {Type: "array", Items: &address},
+			"home":      address,
+			"levels":    {Type: "array", Items: &InputSchemaProperty{Type: "integer", Enum: []interface{}{int64(1), int64(2)}}},
+			"extra":     {Type: "object", AdditionalProperties: &InputSchemaProperty{Type: "string"}},
+		},
+		Required: []string{"id", "unit", "days", "addresses"},
+	}
+
+	if !reflect.DeepEqual(schema, expected) {
+		got, _ := json.Marshal(schema)
+		want, _ := json.Marshal(expected)
+		t.Errorf("unexpected schema\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestGenerateInputSchemaErrors(t *testing.T) {
+	type recursive struct {
+		Children []recursive `json:"children"`
+	}
+
+	type Node struct {
+		*Node
+		Name string `json:"name"`
+	}
+
+	type badEnum struct {
+		Count int `json:"count" jsonschema:"enum=many"`
+	}
+
+	type unknownOption struct {
+		Name string `json:"name" jsonschema:"colour=blue"`
+	}
+
+	type unsupported struct {
+		Callback func() `json:"callback"`
+	}
+
+	tests := []struct {
+		name  string
+		input interface{}
+	}{
+		{"not a struct", "hello"},
+		{"recursive", recursive{}},
+		{"recursive embedding", Node{}},
+		{"bad enum", badEnum{}},
+		{"unknown option", unknownOption{}},
+		{"unsupported type", unsupported{}},
+	}
+
+	for _, test := range tests {
+		_, err := GenerateInputSchema(test.input)
+		if err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
+
+func TestDecodeToolInput(t *testing.T) {
+	input := map[string]interface{}{
+		"id":        "req-1",
+		"unit":      "celsius",
+		"days":      float64(3),
+		"addresses": []interface{}{map[string]interface{}{"street": "King St", "city": "Charleston"}},
+	}
+
+	decoded := schemaTestRequest{}
+	err := DecodeToolInput(input, &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if decoded.ID != "req-1" || decoded.Unit != "celsius" || decoded.Days != 3 {
+		t.Errorf("unexpected decoded input: %+v", decoded)
+	}
+
+	if len(decoded.Addresses) != 1 || decoded.Addresses[0].City != "Charleston" {
+		t.Errorf("unexpected decoded addresses: %+v", decoded.Addresses)
+	}
+}
+
+func TestNewToolFromStruct(t *testing.T) {
+	tool, err := NewToolFromStruct("get_weather", "Get the weather", schemaTestAddress{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tool.Name != "get_weather" || tool.InputSchema.Type != "object" || len(tool.InputSchema.Properties) != 2 {
+		t.Errorf("unexpected tool: %+v", tool)
+	}
+}
+
+func TestGenerateInputSchemaConstraints(t *testing.T) {
+	type constrained struct {
+		Email   string    `json:"email" jsonschema:"format=email,pattern=^.+@.+$,minLength=3,maxLength=254"`
+		Score   float64   `json:"score,omitempty" jsonschema:"exclusiveMinimum=0,exclusiveMaximum=1,default=0.5"`
+		Tags    []string  `json:"tags" jsonschema:"minItems=1,maxItems=5"`
+		Created time.Time `json:"created"`
+	}
+
+	schema, err := GenerateInputSchema(constrained{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	three, max, one, five := 3, 254, 1, 5
+	zero, unit := 0.0, 1.0
+	expected := map[string]InputSchemaProperty{
+		"email":   {Type: "string", Format: "email", Pattern: "^.+@.+$", MinLength: &three, MaxLength: &max},
+		"score":   {Type: "number", ExclusiveMinimum: &zero, ExclusiveMaximum: &unit, Default: 0.5},
+		"tags":    {Type: "array", Items: &InputSchemaProperty{Type: "string"}, MinItems: &one, MaxItems: &five},
+		"created": {Type: "string", Format: "date-time"},
+	}
+
+	if !reflect.DeepEqual(schema.Properties, expected) {
+		got, _ := json.Marshal(schema.Properties)
+		want, _ := json.Marshal(expected)
+		t.Errorf("unexpected properties\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestInputSchemaMarshalSimpleForm(t *testing.T) {
+	schema := InputSchema{
+		Type: "object",
+		Properties: map[string]InputSchemaProperty{
+			"ticker": {
+				Type:        "string",
+				Description: "The stock ticker symbol",
+			},
+		},
+		Required: []string{"ticker"},
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"type":"object","properties":{"ticker":{"type":"string","description":"The stock ticker symbol"}},"required":["ticker"]}`
+	if string(data) != expected {
+		t.Errorf("unexpected json\ngot:  %s\nwant: %s", data, expected)
+	}
+}
+
+func TestInputSchemaMarshalComposition(t *testing.T) {
+	property := InputSchemaProperty{
+		Description: "a location",
+		OneOf: []InputSchemaProperty{
+			{Type: "string", Description: "city name"},
+			{
+				Type:        "object",
+				Description: "coordinates",
+				Properties: map[string]InputSchemaProperty{
+					"lat": {Type: "number", Description: "latitude"},
+				},
+				Required:             []string{"lat"},
+				AdditionalProperties: false,
+			},
+		},
+	}
+
+	data, err := json.Marshal(property)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"description":"a location","oneOf":[{"type":"string","description":"city name"},` +
+		`{"type":"object","description":"coordinates","properties":{"lat":{"type":"number","description":"latitude"}},"required":["lat"],"additionalProperties":false}]}`
+	if string(data) != expected {
+		t.Errorf("unexpected json\ngot:  %s\nwant: %s", data, expected)
+	}
+}
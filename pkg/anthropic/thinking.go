@@ -0,0 +1,95 @@
+package anthropic
+
+import "fmt"
+
+// MinThinkingBudgetTokens is the minimum budget of extended thinking.
+const MinThinkingBudgetTokens = 1024
+
+const ThinkingTypeEnabled = "enabled"
+
+// ThinkingConfig enables extended thinking, letting the model reason in thinking blocks before
+// answering. BudgetTokens counts towards MaxTokensToSample and must be below it.
+type ThinkingConfig struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// NewThinkingConfig creates a ThinkingConfig enabling extended thinking with budgetTokens.
+func NewThinkingConfig(budgetTokens int) *ThinkingConfig {
+	return &ThinkingConfig{
+		Type:         ThinkingTypeEnabled,
+		BudgetTokens: budgetTokens,
+	}
+}
+
+// ThinkingContentBlock holds the reasoning of the model. It must be sent back unchanged, with its
+// signature, when continuing a conversation using tools.
+type ThinkingContentBlock struct {
+	Type      string `json:"type"`
+	Thinking  string `json:"thinking"`
+	Signature string `json:"signature"`
+}
+
+func (t ThinkingContentBlock) isContentBlock() {}
+
+func NewThinkingContentBlock(thinking, signature string) ContentBlock {
+	return ThinkingContentBlock{
+		Type:      "thinking",
+		Thinking:  thinking,
+		Signature: signature,
+	}
+}
+
+// RedactedThinkingContentBlock holds reasoning encrypted by the API, which must be sent back
+// unchanged like thinking blocks.
+type RedactedThinkingContentBlock struct {
+	Type string `json:"type"`
+	Data string `json:"data"`
+}
+
+func (r RedactedThinkingContentBlock) isContentBlock() {}
+
+func NewRedactedThinkingContentBlock(data string) ContentBlock {
+	return RedactedThinkingContentBlock{
+		Type: "redacted_thinking",
+		Data: data,
+	}
+}
+
+// validateThinking checks the thinking budget and the parameters that can't be used with thinking.
+func validateThinking(req *MessageRequest) error {
+	if req.Thinking == nil {
+		return nil
+	}
+
+	capabilities, _ := req.Model.Capabilities()
+	if !capabilities.Thinking && !capabilities.Discovered {
+		return fmt.Errorf("model %s does not support extended thinking", req.Model)
+	}
+
+	if req.Thinking.BudgetTokens < MinThinkingBudgetTokens {
+		return fmt.Errorf("thinking budget_tokens must be at least %d", MinThinkingBudgetTokens)
+	}
+
+	if req.Thinking.BudgetTokens >= req.MaxTokensToSample {
+		return fmt.Errorf("thinking budget_tokens %d must be less than max_tokens %d", req.Thinking.BudgetTokens, req.MaxTokensToSample)
+	}
+
+	if req.Temperature != 0 && req.Temperature != 1 {
+		return fmt.Errorf("temperature cannot be set with thinking enabled")
+	}
+
+	if req.TopK != 0 {
+		return fmt.Errorf("top_k cannot be set with thinking enabled")
+	}
+
+	if req.TopP != 0 && req.TopP < 0.95 {
+		return fmt.Errorf("top_p must be at least 0.95 with thinking enabled")
+	}
+
+	if req.ToolChoice != nil && req.ToolChoice.Type != "auto" && req.ToolChoice.Type != "none" {
+		return fmt.Errorf("tool_choice %s cannot be used with thinking enabled", req.ToolChoice.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestValidateThinking(t *testing.T) {
+	requests := []validateMessageTestCase{
+		{
+			request: NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(2048)),
+		},
+		{
+			request: NewMessageRequest(WithMessageModel(Claude35Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(2048)),
+			expErr:  fmt.Sprintf("model %s does not support extended thinking", Claude35Sonnet),
+		},
+		{
+			request: NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(512)),
+			expErr:  "thinking budget_tokens must be at least 1024",
+		},
+		{
+			request: NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(2048), WithMessageThinking(2048)),
+			expErr:  "thinking budget_tokens 2048 must be less than max_tokens 2048",
+		},
+		{
+			request: NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(2048), WithMessageTemperature(0.5)),
+			expErr:  "temperature cannot be set with thinking enabled",
+		},
+		{
+			request: NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(2048), WithMessageTopK(5)),
+			expErr:  "top_k cannot be set with thinking enabled",
+		},
+		{
+			request: NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(2048), WithToolChoice("any", "")),
+			expErr:  "tool_choice any cannot be used with thinking enabled",
+		},
+	}
+
+	for _, test := range requests {
+		err := ValidateMessageRequest(test.request)
+		if test.expErr == "" {
+			if err != nil {
+				t.Errorf("Unexpected error %s", err.Error())
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expErr {
+			t.Errorf("Expected error %s, got %v", test.expErr, err)
+		}
+	}
+}
+
+func TestThinkingRequestJSON(t *testing.T) {
+	request := NewMessageRequest(WithMessageModel(Claude37Sonnet), WithMessageMaxTokens(4096), WithMessageThinking(2048))
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body := map[string]json.RawMessage{}
+	err = json.Unmarshal(data, &body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"type":"enabled","budget_tokens":2048}`
+	if string(body["thinking"]) != expected {
+		t.Errorf("expected thinking %s, got %s", expected, body["thinking"])
+	}
+}
+
+func TestThinkingBlocksRoundTrip(t *testing.T) {
+	data := `{"id":"msg_01","type":"message","role":"assistant","content":[` +
+		`{"type":"thinking","thinking":"The user wants the weather.","signature":"sig-123"},` +
+		`{"type":"redacted_thinking","data":"encrypted-456"},` +
+		`{"type":"tool_use","id":"toolu_01","name":"get_weather","input":{"city":"Charleston"}}` +
+		`],"stop_reason":"tool_use"}`
+
+	response := &MessageResponse{}
+	err := json.Unmarshal([]byte(data), response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Thinking() != "The user wants the weather." {
+		t.Errorf("unexpected thinking %q", response.Thinking())
+	}
+
+	expected := []ContentBlock{
+		NewThinkingContentBlock("The user wants the weather.", "sig-123"),
+		NewRedactedThinkingContentBlock("encrypted-456"),
+	}
+	if !reflect.DeepEqual(response.Content[:2], expected) {
+		t.Errorf("unexpected content %+v", response.Content)
+	}
+
+	// the blocks are sent back unchanged in the next turn
+	request := NewMessageRequest(WithMessageModel(Claude37Sonnet))
+	request.AddAssistantMessage(response.Content...)
+
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded := &MessageRequest{}
+	err = json.Unmarshal(requestData, decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded.Messages[0].Content, response.Content) {
+		t.Errorf("expected the content to round-trip, got %+v", decoded.Messages[0].Content)
+	}
+}
+
+func TestMessageStreamAccumulatorThinking(t *testing.T) {
+	events := []struct {
+		eventType MessageEventType
+		event     string
+	}{
+		{MessageEventTypeMessageStart, `{"type": "message_start", "message": {"id": "msg_01", "type": "message", "role": "assistant", "content": [], "model": "claude-3-7-sonnet-20250219", "usage": {"input_tokens": 25, "output_tokens": 1}}}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 0, "content_block": {"type": "thinking", "thinking": ""}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "Let me "}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "think."}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 0, "delta": {"type": "signature_delta", "signature": "sig-123"}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 0}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 1, "content_block": {"type": "redacted_thinking", "data": "encrypted-456"}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 1}`},
+		{MessageEventTypeContentBlockStart, `{"type": "content_block_start", "index": 2, "content_block": {"type": "text", "text": ""}}`},
+		{MessageEventTypeContentBlockDelta, `{"type": "content_block_delta", "index": 2, "delta": {"type": "text_delta", "text": "Done."}}`},
+		{MessageEventTypeContentBlockStop, `{"type": "content_block_stop", "index": 2}`},
+		{MessageEventTypeMessageDelta, `{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 42}}`},
+		{MessageEventTypeMessageStop, `{"type": "message_stop"}`},
+	}
+
+	parser := NewMessageEventParser()
+	accumulator := NewMessageStreamAccumulator()
+	for _, test := range events {
+		event, err := parser.Parse(test.eventType, test.event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if test.eventType == MessageEventTypeContentBlockStop && event.Index == 0 {
+			if event.ContentBlock.Thinking != "Let me think." || event.ContentBlock.Signature != "sig-123" {
+				t.Errorf("unexpected assembled thinking block %+v", event.ContentBlock)
+			}
+		}
+
+		err = accumulator.Add(event)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []ContentBlock{
+		ThinkingContentBlock{Type: "thinking", Thinking: "Let me think.", Signature: "sig-123"},
+		RedactedThinkingContentBlock{Type: "redacted_thinking", Data: "encrypted-456"},
+		TextContentBlock{Type: "text", Text: "Done."},
+	}
+
+	response := accumulator.Response()
+	if !reflect.DeepEqual(response.Content, expected) {
+		t.Errorf("unexpected content, got: %+v, want: %+v", response.Content, expected)
+	}
+}
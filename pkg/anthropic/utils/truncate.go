@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Truncate shortens s to at most limit bytes, without splitting a UTF-8 character, and notes how
+// much was cut. A negative limit disables truncation.
+func Truncate(s string, limit int) string {
+	if limit < 0 || len(s) <= limit {
+		return s
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s... (%d bytes truncated)", s[:cut], len(s)-cut)
+}
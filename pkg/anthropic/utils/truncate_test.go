@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit int
+		want  string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello... (6 bytes truncated)"},
+		{"héllo", 2, "h... (5 bytes truncated)"},
+		{"hello", -1, "hello"},
+		{"hello", 0, "... (5 bytes truncated)"},
+	}
+
+	for _, test := range tests {
+		got := Truncate(test.s, test.limit)
+		if got != test.want {
+			t.Errorf("Truncate(%q, %d) = %q; want %q", test.s, test.limit, got, test.want)
+		}
+	}
+}
@@ -7,19 +7,30 @@ func ValidateMessageRequest(req *MessageRequest) error {
 		return fmt.Errorf("cannot use Message with streaming enabled, use MessageStream instead")
 	}
 
-	if !req.Model.IsMessageCompatible() {
-		return fmt.Errorf("model %s is not compatible with the message endpoint", req.Model)
-	}
-
-	if !req.Model.IsImageCompatible() && req.ContainsImageContent() {
-		return fmt.Errorf("model %s does not support image content", req.Model)
+	err := validateModel(req, "message")
+	if err != nil {
+		return err
 	}
 
 	if req.CountImageContent() > 20 {
 		return fmt.Errorf("too many image content blocks, maximum is 20")
 	}
 
-	return nil
+	err = validateImages(req)
+	if err != nil {
+		return err
+	}
+
+	if req.CountCacheControl() > MaxCacheBreakpoints {
+		return fmt.Errorf("too many cache_control breakpoints, maximum is %d", MaxCacheBreakpoints)
+	}
+
+	err = validateMaxTokens(req)
+	if err != nil {
+		return err
+	}
+
+	return validateThinking(req)
 }
 
 func ValidateMessageStreamRequest(req *MessageRequest) error {
@@ -27,17 +38,84 @@ func ValidateMessageStreamRequest(req *MessageRequest) error {
 		return fmt.Errorf("cannot use MessageStream with streaming disabled, use Message instead")
 	}
 
-	if !req.Model.IsMessageCompatible() {
-		return fmt.Errorf("model %s is not compatible with the messagestream endpoint", req.Model)
+	err := validateModel(req, "messagestream")
+	if err != nil {
+		return err
 	}
 
-	if !req.Model.IsImageCompatible() && req.ContainsImageContent() {
-		return fmt.Errorf("model %s does not support image content", req.Model)
+	if req.CountImageContent() > 20 {
+		return fmt.Errorf("too many image content blocks, maximum is 20")
+	}
+
+	err = validateImages(req)
+	if err != nil {
+		return err
+	}
+
+	if req.CountCacheControl() > MaxCacheBreakpoints {
+		return fmt.Errorf("too many cache_control breakpoints, maximum is %d", MaxCacheBreakpoints)
+	}
+
+	err = validateMaxTokens(req)
+	if err != nil {
+		return err
+	}
+
+	return validateThinking(req)
+}
+
+func ValidateCountTokensRequest(req *MessageRequest) error {
+	err := validateModel(req, "count tokens")
+	if err != nil {
+		return err
 	}
 
 	if req.CountImageContent() > 20 {
 		return fmt.Errorf("too many image content blocks, maximum is 20")
 	}
 
+	err = validateImages(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateModel checks that the model of req is registered as compatible with the message endpoint
+// and supports the content and tools of the request. The capabilities of discovered models are only
+// inferred, so their requests are left for the API to reject.
+func validateModel(req *MessageRequest, endpoint string) error {
+	capabilities, _ := req.Model.Capabilities()
+	if !capabilities.Message {
+		return fmt.Errorf("model %s is not compatible with the %s endpoint", req.Model, endpoint)
+	}
+
+	if capabilities.Discovered {
+		return validateBuiltinTools(req, capabilities)
+	}
+
+	if !capabilities.Image && req.ContainsImageContent() {
+		return fmt.Errorf("model %s does not support image content", req.Model)
+	}
+
+	if !capabilities.ToolUse && len(req.Tools) > 0 {
+		return fmt.Errorf("model %s does not support tool use", req.Model)
+	}
+
+	if !capabilities.ComputerUse && req.ContainsComputerUseTools() {
+		return fmt.Errorf("model %s does not support computer use", req.Model)
+	}
+
+	return validateBuiltinTools(req, capabilities)
+}
+
+// validateMaxTokens checks max_tokens against the maximum output of the model, when it is known.
+func validateMaxTokens(req *MessageRequest) error {
+	capabilities, _ := req.Model.Capabilities()
+	if !capabilities.Discovered && capabilities.MaxOutputTokens > 0 && req.MaxTokensToSample > capabilities.MaxOutputTokens {
+		return fmt.Errorf("max_tokens %d exceeds the maximum of %d output tokens of model %s", req.MaxTokensToSample, capabilities.MaxOutputTokens, req.Model)
+	}
+
 	return nil
 }
@@ -135,3 +135,57 @@ func TestValidateMessageStreamRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMessageRequestCapabilities(t *testing.T) {
+	requests := []validateMessageTestCase{
+		{
+			request: &MessageRequest{
+				Model: Claude35Haiku,
+			},
+		},
+		{
+			request: &MessageRequest{
+				Model: ClaudeV2_1,
+				Tools: []Tool{{Name: "get_weather"}},
+			},
+			expErr: fmt.Sprintf("model %s does not support tool use", ClaudeV2_1),
+		},
+		{
+			request: &MessageRequest{
+				Model:             Claude3Haiku,
+				MaxTokensToSample: 8192,
+			},
+			expErr: fmt.Sprintf("max_tokens 8192 exceeds the maximum of 4096 output tokens of model %s", Claude3Haiku),
+		},
+	}
+
+	for _, test := range requests {
+		err := ValidateMessageRequest(test.request)
+		if test.expErr == "" {
+			if err != nil {
+				t.Errorf("Unexpected error %s", err.Error())
+			}
+			continue
+		}
+
+		if err == nil || err.Error() != test.expErr {
+			t.Errorf("Expected error %s, got %v", test.expErr, err)
+		}
+	}
+}
+
+func TestValidateMessageRequestDiscoveredModel(t *testing.T) {
+	// claude-3-haiku doesn't support thinking nor more than 4096 output tokens, but the capabilities
+	// of a new version are only inferred from it
+	model := Model("claude-3-haiku-20990102")
+	RegisterModelInfo(ModelInfo{ID: string(model)})
+
+	err := ValidateMessageRequest(&MessageRequest{
+		Model:             model,
+		MaxTokensToSample: 8192,
+		Thinking:          NewThinkingConfig(2048),
+	})
+	if err != nil {
+		t.Errorf("Expected the discovered model to be left for the API to check, got %v", err)
+	}
+}
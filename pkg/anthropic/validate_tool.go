@@ -0,0 +1,329 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ToolInputFieldError describes a single problem found in a tool input. Path locates the offending
+// value, e.g. "addresses[0].city"; it is empty for problems with the input as a whole.
+type ToolInputFieldError struct {
+	Path    string
+	Message string
+}
+
+func (e ToolInputFieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ToolInputValidationError is returned when a tool input does not match the tool's InputSchema.
+type ToolInputValidationError struct {
+	ToolName string
+	Errors   []ToolInputFieldError
+}
+
+func (e *ToolInputValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Error()
+	}
+
+	if e.ToolName == "" {
+		return fmt.Sprintf("invalid tool input: %s", strings.Join(messages, "; "))
+	}
+	return fmt.Sprintf("invalid input for tool %s: %s", e.ToolName, strings.Join(messages, "; "))
+}
+
+// ToolResult creates an is_error tool result reporting the validation errors back to the model so
+// it can correct its input.
+func (e *ToolInputValidationError) ToolResult(toolUseID string) ContentBlock {
+	return NewToolResultContentBlock(toolUseID, e.Error(), true)
+}
+
+// ValidateToolInput checks input, typically the Input of a tool_use block, against schema. It
+// returns a *ToolInputValidationError listing every mismatch, or nil if the input is valid.
+func ValidateToolInput(schema InputSchema, input interface{}) error {
+	normalized, err := normalizeJSONValue(input)
+	if err != nil {
+		return &ToolInputValidationError{
+			Errors: []ToolInputFieldError{{Message: fmt.Sprintf("input is not valid JSON: %s", err)}},
+		}
+	}
+
+	root := InputSchemaProperty{
+		Type:                 schema.Type,
+		Properties:           schema.Properties,
+		Required:             schema.Required,
+		AdditionalProperties: schema.AdditionalProperties,
+	}
+
+	validator := &toolInputValidator{}
+	validator.validate("", root, normalized)
+	if len(validator.errors) > 0 {
+		return &ToolInputValidationError{Errors: validator.errors}
+	}
+
+	return nil
+}
+
+// ValidateToolInput checks input against the InputSchema of the tool called name declared in the
+// request's Tools.
+func (r *MessageRequest) ValidateToolInput(name string, input interface{}) error {
+	for _, tool := range r.Tools {
+		if tool.Name != name {
+			continue
+		}
+
+		err := ValidateToolInput(tool.InputSchema, input)
+		if validationErr, ok := err.(*ToolInputValidationError); ok {
+			validationErr.ToolName = name
+		}
+		return err
+	}
+
+	return &ToolInputValidationError{
+		ToolName: name,
+		Errors:   []ToolInputFieldError{{Message: "tool is not declared in the request"}},
+	}
+}
+
+type toolInputValidator struct {
+	errors []ToolInputFieldError
+}
+
+func (v *toolInputValidator) fail(path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ToolInputFieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *toolInputValidator) validate(path string, schema InputSchemaProperty, value interface{}) {
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		v.fail(path, "expected %s, got %s", schema.Type, jsonTypeName(value))
+		return
+	}
+
+	if len(schema.Enum) > 0 && !containsJSONValue(schema.Enum, value) {
+		v.fail(path, "must be one of %s", formatJSONValues(schema.Enum))
+	}
+
+	if schema.Const != nil && !containsJSONValue([]interface{}{schema.Const}, value) {
+		v.fail(path, "must be %s", formatJSONValues([]interface{}{schema.Const}))
+	}
+
+	switch typed := value.(type) {
+	case string:
+		v.validateString(path, schema, typed)
+	case float64:
+		v.validateNumber(path, schema, typed)
+	case []interface{}:
+		v.validateArray(path, schema, typed)
+	case map[string]interface{}:
+		v.validateObject(path, schema, typed)
+	}
+
+	v.validateComposition(path, schema, value)
+}
+
+func (v *toolInputValidator) validateString(path string, schema InputSchemaProperty, value string) {
+	length := utf8.RuneCountInString(value)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		v.fail(path, "must be at least %d characters long", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		v.fail(path, "must be at most %d characters long", *schema.MaxLength)
+	}
+
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			v.fail(path, "schema pattern %q is invalid: %s", schema.Pattern, err)
+		} else if !re.MatchString(value) {
+			v.fail(path, "must match pattern %q", schema.Pattern)
+		}
+	}
+}
+
+func (v *toolInputValidator) validateNumber(path string, schema InputSchemaProperty, value float64) {
+	if schema.Minimum != nil && value < *schema.Minimum {
+		v.fail(path, "must be greater than or equal to %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		v.fail(path, "must be less than or equal to %v", *schema.Maximum)
+	}
+	if schema.ExclusiveMinimum != nil && value <= *schema.ExclusiveMinimum {
+		v.fail(path, "must be greater than %v", *schema.ExclusiveMinimum)
+	}
+	if schema.ExclusiveMaximum != nil && value >= *schema.ExclusiveMaximum {
+		v.fail(path, "must be less than %v", *schema.ExclusiveMaximum)
+	}
+}
+
+func (v *toolInputValidator) validateArray(path string, schema InputSchemaProperty, value []interface{}) {
+	if schema.MinItems != nil && len(value) < *schema.MinItems {
+		v.fail(path, "must contain at least %d items", *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+		v.fail(path, "must contain at most %d items", *schema.MaxItems)
+	}
+
+	if schema.UniqueItems {
+		for i := range value {
+			if containsJSONValue(value[:i], value[i]) {
+				v.fail(path, "must not contain duplicate items")
+				break
+			}
+		}
+	}
+
+	if schema.Items == nil {
+		return
+	}
+
+	for i, item := range value {
+		v.validate(fmt.Sprintf("%s[%d]", path, i), *schema.Items, item)
+	}
+}
+
+func (v *toolInputValidator) validateObject(path string, schema InputSchemaProperty, value map[string]interface{}) {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			v.fail(joinPath(path, name), "is required")
+		}
+	}
+
+	// iterate in a stable order so errors are reported deterministically
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if property, ok := schema.Properties[name]; ok {
+			v.validate(joinPath(path, name), property, value[name])
+			continue
+		}
+
+		switch additional := schema.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				v.fail(joinPath(path, name), "is not allowed")
+			}
+		case *InputSchemaProperty:
+			if additional != nil {
+				v.validate(joinPath(path, name), *additional, value[name])
+			}
+		case InputSchemaProperty:
+			v.validate(joinPath(path, name), additional, value[name])
+		}
+	}
+}
+
+func (v *toolInputValidator) validateComposition(path string, schema InputSchemaProperty, value interface{}) {
+	for _, sub := range schema.AllOf {
+		v.validate(path, sub, value)
+	}
+
+	if len(schema.AnyOf) > 0 && countMatching(path, schema.AnyOf, value) == 0 {
+		v.fail(path, "must match at least one of the allowed schemas")
+	}
+
+	if len(schema.OneOf) > 0 && countMatching(path, schema.OneOf, value) != 1 {
+		v.fail(path, "must match exactly one of the allowed schemas")
+	}
+}
+
+func countMatching(path string, schemas []InputSchemaProperty, value interface{}) int {
+	matching := 0
+	for _, sub := range schemas {
+		validator := &toolInputValidator{}
+		validator.validate(path, sub, value)
+		if len(validator.errors) == 0 {
+			matching++
+		}
+	}
+	return matching
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return jsonTypeName(value) == schemaType
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// normalizeJSONValue converts value to the generic form produced by encoding/json, so inputs built
+// from Go structs or literals can be validated the same way as decoded API responses.
+func normalizeJSONValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+func containsJSONValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		normalized, err := normalizeJSONValue(candidate)
+		if err != nil {
+			continue
+		}
+		if reflect.DeepEqual(normalized, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatJSONValues(values []interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Sprint(values)
+	}
+	return string(data)
+}
@@ -0,0 +1,128 @@
+package anthropic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func weatherToolSchema() InputSchema {
+	one, seven := 1.0, 7.0
+	return InputSchema{
+		Type: "object",
+		Properties: map[string]InputSchemaProperty{
+			"city": {Type: "string", Description: "city to get the weather for"},
+			"unit": {Type: "string", Enum: []interface{}{"celsius", "fahrenheit"}},
+			"days": {Type: "integer", Minimum: &one, Maximum: &seven},
+			"stations": {
+				Type: "array",
+				Items: &InputSchemaProperty{
+					Type: "object",
+					Properties: map[string]InputSchemaProperty{
+						"id": {Type: "string"},
+					},
+					Required: []string{"id"},
+				},
+			},
+		},
+		Required:             []string{"city", "unit"},
+		AdditionalProperties: false,
+	}
+}
+
+func TestValidateToolInputValid(t *testing.T) {
+	input := map[string]interface{}{
+		"city":     "Charleston",
+		"unit":     "fahrenheit",
+		"days":     float64(3),
+		"stations": []interface{}{map[string]interface{}{"id": "KCHS"}},
+	}
+
+	err := ValidateToolInput(weatherToolSchema(), input)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateToolInputInvalid(t *testing.T) {
+	input := map[string]interface{}{
+		"unit":     "kelvin",
+		"days":     2.5,
+		"stations": []interface{}{map[string]interface{}{"name": "Charleston"}},
+		"extra":    true,
+	}
+
+	err := ValidateToolInput(weatherToolSchema(), input)
+
+	var validationErr *ToolInputValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ToolInputValidationError, got %v", err)
+	}
+
+	expected := []ToolInputFieldError{
+		{Path: "city", Message: "is required"},
+		{Path: "days", Message: "expected integer, got number"},
+		{Path: "extra", Message: "is not allowed"},
+		{Path: "stations[0].id", Message: "is required"},
+		{Path: "unit", Message: `must be one of ["celsius","fahrenheit"]`},
+	}
+
+	if !reflect.DeepEqual(validationErr.Errors, expected) {
+		t.Errorf("unexpected errors\ngot:  %+v\nwant: %+v", validationErr.Errors, expected)
+	}
+}
+
+func TestValidateToolInputConstraints(t *testing.T) {
+	two := 2
+	schema := InputSchema{
+		Type: "object",
+		Properties: map[string]InputSchemaProperty{
+			"code":  {Type: "string", Pattern: "^[A-Z]+$", MaxLength: &two},
+			"tags":  {Type: "array", Items: &InputSchemaProperty{Type: "string"}, UniqueItems: true},
+			"where": {OneOf: []InputSchemaProperty{{Type: "string"}, {Type: "integer"}}},
+		},
+	}
+
+	input := map[string]interface{}{
+		"code":  "abc",
+		"tags":  []interface{}{"a", "a"},
+		"where": true,
+	}
+
+	err := ValidateToolInput(schema, input)
+
+	var validationErr *ToolInputValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ToolInputValidationError, got %v", err)
+	}
+
+	if len(validationErr.Errors) != 4 {
+		t.Errorf("expected 4 errors, got %+v", validationErr.Errors)
+	}
+}
+
+func TestMessageRequestValidateToolInput(t *testing.T) {
+	request := &MessageRequest{
+		Tools: []Tool{{Name: "get_weather", InputSchema: weatherToolSchema()}},
+	}
+
+	err := request.ValidateToolInput("get_weather", map[string]interface{}{"city": "Charleston"})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	expErr := "invalid input for tool get_weather: unit: is required"
+	if err.Error() != expErr {
+		t.Errorf("expected error %q, got %q", expErr, err.Error())
+	}
+
+	result := err.(*ToolInputValidationError).ToolResult("toolu_01").(ToolResultContentBlock)
+	if !result.IsError || result.ToolUseID != "toolu_01" || result.Content != expErr {
+		t.Errorf("unexpected tool result %+v", result)
+	}
+
+	err = request.ValidateToolInput("get_stock_price", map[string]interface{}{})
+	if err == nil {
+		t.Error("expected error for undeclared tool, got nil")
+	}
+}
@@ -30,5 +30,5 @@ func main() {
 		panic(err)
 	}
 
-	fmt.Println(response.Content)
+	fmt.Println(response.Text())
 }
@@ -3,8 +3,6 @@ package main
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic"
 	"github.com/madebywelch/anthropic-go/v4/pkg/anthropic/client/native"
@@ -35,27 +33,25 @@ func main() {
 
 	rCh, errCh := client.MessageStream(ctx, request)
 
-	final := strings.Builder{}
-	chunk := &anthropic.MessageStreamResponse{}
-	done := false
-
-	for {
-		select {
-		case chunk = <-rCh:
-			final.WriteString(chunk.Delta.Text)
-			fmt.Print(chunk.Delta.Text)
-		case err := <-errCh:
-			fmt.Printf("\n\nError: %s\n\n", err)
-			done = true
-			break
-		}
-		if chunk.Type == "message_stop" || done {
-			break
+	accumulator := anthropic.NewMessageStreamAccumulator()
+	for chunk := range rCh {
+		fmt.Print(chunk.Delta.Text)
+
+		if err := accumulator.Add(chunk); err != nil {
+			panic(err)
 		}
-		time.Sleep(100 * time.Millisecond)
 	}
 
+	if err := <-errCh; err != nil {
+		fmt.Printf("\n\nError: %s\n\n", err)
+		return
+	}
+
+	response := accumulator.Response()
+
+	fmt.Println()
 	fmt.Println("-------------------FINAL RESULT----------------------")
-	fmt.Println(final.String())
+	fmt.Println(response.Text())
+	fmt.Printf("stop reason: %s, input tokens: %d, output tokens: %d\n", response.StopReason, response.Usage.InputTokens, response.Usage.OutputTokens)
 	fmt.Println("-----------------------------------------------------")
 }
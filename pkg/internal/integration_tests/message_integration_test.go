@@ -236,8 +236,8 @@ func TestMessageIntegration(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Unexpected error: %v", err)
 				}
-				if !strings.Contains(response.Content[0].Text, "Paris") {
-					t.Errorf("Expected response to mention Paris, got: %s", response.Content[0].Text)
+				if !strings.Contains(response.Text(), "Paris") {
+					t.Errorf("Expected response to mention Paris, got: %s", response.Text())
 				}
 			},
 		},
@@ -256,8 +256,8 @@ func TestMessageIntegration(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Unexpected error: %v", err)
 				}
-				if !strings.Contains(response.Content[0].Text, "Saturn") {
-					t.Errorf("Expected response to mention Saturn, got: %s", response.Content[0].Text)
+				if !strings.Contains(response.Text(), "Saturn") {
+					t.Errorf("Expected response to mention Saturn, got: %s", response.Text())
 				}
 			},
 		},
@@ -275,8 +275,8 @@ func TestMessageIntegration(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Unexpected error: %v", err)
 				}
-				if !strings.Contains(response.Content[0].Text, "\n") {
-					t.Errorf("Expected response to be in rhyme (contain newlines), got: %s", response.Content[0].Text)
+				if !strings.Contains(response.Text(), "\n") {
+					t.Errorf("Expected response to be in rhyme (contain newlines), got: %s", response.Text())
 				}
 			},
 		},
@@ -296,7 +296,7 @@ func TestMessageIntegration(t *testing.T) {
 					t.Fatalf("Unexpected error: %v", err)
 				}
 				// We can't check for randomness easily, but we can ensure a response was received
-				if response.Content[0].Text == "" {
+				if response.Text() == "" {
 					t.Errorf("Expected a non-empty response")
 				}
 			},
@@ -315,8 +315,8 @@ func TestMessageIntegration(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Unexpected error: %v", err)
 				}
-				if strings.ContainsAny(response.Content[0].Text, ".!") {
-					t.Errorf("Expected response without '.' or '!', got: %s", response.Content[0].Text)
+				if strings.ContainsAny(response.Text(), ".!") {
+					t.Errorf("Expected response without '.' or '!', got: %s", response.Text())
 				}
 			},
 		},
@@ -28,13 +28,13 @@ func TestToolIntegration(t *testing.T) {
 			t.Fatalf("Error sending message: %v", err)
 		}
 
-		toolUseID, assistantMessageBlock := processResponse(t, response)
-		request.AddAssistantMessage(assistantMessageBlock...)
+		toolUseID := processResponse(t, response)
+		request.AddAssistantMessage(response.Content...)
 
 		request.Messages = append(request.Messages, createToolResultMessage(toolUseID))
 
-		if len(request.Messages) != len(response.Content)+1 {
-			t.Errorf("Expected %d messages, got %d", len(response.Content)+1, len(request.Messages))
+		if len(request.Messages) != 3 {
+			t.Errorf("Expected %d messages, got %d", 3, len(request.Messages))
 		}
 
 		finalResponse, err := client.Message(context.Background(), request)
@@ -42,10 +42,10 @@ func TestToolIntegration(t *testing.T) {
 			t.Fatalf("Error sending final message: %v", err)
 		}
 
-		if !strings.Contains(finalResponse.Content[0].Text, "52") {
-			t.Errorf("Response should contain the temperature '52', got: %s", finalResponse.Content[0].Text)
+		if !strings.Contains(finalResponse.Text(), "52") {
+			t.Errorf("Response should contain the temperature '52', got: %s", finalResponse.Text())
 		}
-		t.Logf("Tool Response: %s", finalResponse.Content[0].Text)
+		t.Logf("Tool Response: %s", finalResponse.Text())
 	})
 }
 
@@ -84,22 +84,20 @@ func createWeatherRequest() *anthropic.MessageRequest {
 	}
 }
 
-func processResponse(t *testing.T, response *anthropic.MessageResponse) (string, []anthropic.ContentBlock) {
+func processResponse(t *testing.T, response *anthropic.MessageResponse) string {
 	t.Helper()
 	var toolUseID string
-	assistantMessageBlock := []anthropic.ContentBlock{anthropic.NewTextContentBlock(response.Content[0].Text)}
 
-	for _, part := range response.Content {
-		if part.Type == "tool_use" {
-			toolUseID = part.ID
-			assistantMessageBlock = append(assistantMessageBlock, anthropic.NewToolUseContentBlock(part.ID, "get_weather", part.Input))
+	for _, toolUse := range response.ToolUses() {
+		if toolUse.Name == "get_weather" {
+			toolUseID = toolUse.ID
 		}
 	}
 
 	if toolUseID == "" {
 		t.Fatal("Tool Use ID is empty")
 	}
-	return toolUseID, assistantMessageBlock
+	return toolUseID
 }
 
 func createToolResultMessage(toolUseID string) anthropic.MessagePartRequest {